@@ -0,0 +1,130 @@
+package mediator
+
+import (
+	"context"
+	"reflect"
+)
+
+// adapter[TRequest, TResult] adapts a command registered for a type other than
+// TRequest to execute requests of type TRequest, returning a specific result
+// type.  The command is registered either for an interface implemented by
+// TRequest or, with pointer equivalence, for the type to which TRequest points
+// (in which case the request is dereferenced).  The command is called using
+// reflection.
+type adapter[TRequest any, TResult any] struct {
+	cmd   any
+	deref bool
+}
+
+// validatingAdapter[TRequest, TResult] adapts a command which also implements
+// Validator for the type for which it is registered.
+type validatingAdapter[TRequest any, TResult any] struct {
+	adapter[TRequest, TResult]
+}
+
+// requestChecker is implemented by an adapter to check a request before it is
+// authorized, validated and executed.
+type requestChecker interface {
+	checkRequest(rq any) error
+}
+
+// adapt returns a CommandHandler[TRequest, TResult] executing requests using a
+// command registered for the specified request type.  If the command does not
+// return the result type, the command itself is returned (resulting in a
+// ResultTypeError).
+func adapt[TRequest any, TResult any](cmd any, rqt reflect.Type, deref bool) any {
+	cmdt := reflect.TypeOf(cmd)
+	m, ok := cmdt.MethodByName("Execute")
+	if !ok || m.Type.NumOut() != 2 || m.Type.Out(0) != reflect.TypeOf((*TResult)(nil)).Elem() {
+		return cmd
+	}
+
+	a := adapter[TRequest, TResult]{cmd: cmd, deref: deref}
+	if m, ok := cmdt.MethodByName("Validate"); ok && m.Type.NumIn() == 3 && m.Type.In(2) == rqt {
+		return validatingAdapter[TRequest, TResult]{a}
+	}
+	return a
+}
+
+// wrapped satisfies the wrapper interface, so that optional interfaces which
+// determine how a request is executed are those of the adapted command.
+func (a adapter[TRequest, TResult]) wrapped() any {
+	return a.cmd
+}
+
+// checkRequest returns a ValidationError if the request is a nil pointer to be
+// dereferenced.
+func (a adapter[TRequest, TResult]) checkRequest(rq any) error {
+	if a.deref && reflect.ValueOf(rq).IsNil() {
+		return ValidationError{E: ErrNilRequest}
+	}
+	return nil
+}
+
+// args returns the arguments with which to call a method of the adapted command.
+func (a adapter[TRequest, TResult]) args(ctx context.Context, rq TRequest) []reflect.Value {
+	v := reflect.ValueOf(rq)
+	if a.deref {
+		v = v.Elem()
+	}
+	return []reflect.Value{reflect.ValueOf(&ctx).Elem(), v}
+}
+
+// Execute satisfies the CommandHandler interface, calling the Execute method of
+// the adapted command.
+func (a adapter[TRequest, TResult]) Execute(ctx context.Context, rq TRequest) (TResult, error) {
+	out := reflect.ValueOf(a.cmd).MethodByName("Execute").Call(a.args(ctx, rq))
+	result, _ := out[0].Interface().(TResult)
+	err, _ := out[1].Interface().(error)
+	return result, err
+}
+
+// Validate satisfies the Validator interface, calling the Validate method of
+// the adapted command.
+func (a validatingAdapter[TRequest, TResult]) Validate(ctx context.Context, rq TRequest) error {
+	out := reflect.ValueOf(a.cmd).MethodByName("Validate").Call(a.args(ctx, rq))
+	err, _ := out[0].Interface().(error)
+	return err
+}
+
+// adaptedAuthorizer[TRequest] adapts a command implementing Authorizer for a
+// type other than TRequest to authorize requests of type TRequest.
+type adaptedAuthorizer[TRequest any] struct {
+	cmd   any
+	deref bool
+}
+
+// asAdaptedAuthorizer returns an Authorizer[TRequest] for a command with an
+// Authorize method accepting an interface implemented by TRequest or, with
+// pointer equivalence, the type to which TRequest points.
+func asAdaptedAuthorizer[TRequest any](cmd any) (Authorizer[TRequest], bool) {
+	if cmd == nil {
+		return nil, false
+	}
+	m, ok := reflect.TypeOf(cmd).MethodByName("Authorize")
+	if !ok || m.Type.NumIn() != 4 || m.Type.NumOut() != 1 {
+		return nil, false
+	}
+
+	rqt, param := reflect.TypeOf((*TRequest)(nil)).Elem(), m.Type.In(3)
+	switch {
+	case param.Kind() == reflect.Interface && rqt.Implements(param):
+		return adaptedAuthorizer[TRequest]{cmd: cmd}, true
+	case pointerEquivalence && rqt.Kind() == reflect.Pointer && rqt.Elem() == param:
+		return adaptedAuthorizer[TRequest]{cmd: cmd, deref: true}, true
+	}
+	return nil, false
+}
+
+// Authorize satisfies the Authorizer interface, calling the Authorize method of
+// the adapted command.
+func (a adaptedAuthorizer[TRequest]) Authorize(ctx context.Context, principal any, rq TRequest) error {
+	v := reflect.ValueOf(rq)
+	if a.deref {
+		v = v.Elem()
+	}
+	in := []reflect.Value{reflect.ValueOf(&ctx).Elem(), reflect.ValueOf(&principal).Elem(), v}
+	out := reflect.ValueOf(a.cmd).MethodByName("Authorize").Call(in)
+	err, _ := out[0].Interface().(error)
+	return err
+}
@@ -0,0 +1,180 @@
+package mediator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// AuditOutcome classifies the outcome of a request recorded in an AuditRecord.
+type AuditOutcome string
+
+const (
+	AuditSuccess         AuditOutcome = "success"           // the command executed successfully
+	AuditNoCommand       AuditOutcome = "no-command"        // no command was registered for the request type
+	AuditResultTypeError AuditOutcome = "result-type-error" // the command does not return the expected result type
+	AuditUnauthorized    AuditOutcome = "unauthorized"      // the request was not authorized (UnauthorizedError)
+	AuditForbidden       AuditOutcome = "forbidden"         // the request was denied (ForbiddenError)
+	AuditInvalid         AuditOutcome = "invalid"           // the request failed validation (ValidationError)
+	AuditError           AuditOutcome = "error"             // the command returned an error
+	AuditPanic           AuditOutcome = "panic"             // the command panicked (PanicError)
+)
+
+// AuditRecord records the execution of a request by the mediator.
+type AuditRecord struct {
+	Time        time.Time     `json:"time"`
+	RequestType string        `json:"requestType"`
+	HandlerType string        `json:"handlerType,omitempty"`
+	Principal   any           `json:"principal,omitempty"`
+	Request     any           `json:"request"`
+	Outcome     AuditOutcome  `json:"outcome"`
+	Error       string        `json:"error,omitempty"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// AuditSink is the interface implemented by a destination for AuditRecords.
+//
+// Audit is called synchronously after every request is executed, so should
+// not block.  An AuditSink must be safe for concurrent use.
+type AuditSink interface {
+	Audit(context.Context, AuditRecord)
+}
+
+// Redacter is an optional interface that may be implemented by a request
+// type to provide a representation of a request that is safe to record in an
+// audit trail, e.g. with passwords or personal information removed.
+//
+// If a request does not implement Redacter it is recorded unmodified.
+type Redacter interface {
+	Redacted() any
+}
+
+var auditSink AuditSink
+
+// now provides the current time.
+//
+// The function is a variable to facilitate module unit tests.
+var now = time.Now
+
+// SetAuditSink establishes the sink to which an AuditRecord is sent after
+// every request is executed.  Specifying a nil sink disables auditing.
+func SetAuditSink(sink AuditSink) {
+	auditSink = sink
+}
+
+// audit sends an AuditRecord to the audit sink, if one has been established.
+func audit(ctx context.Context, start time.Time, rq any, cmd any, err error) {
+	if auditSink == nil {
+		return
+	}
+
+	rec := AuditRecord{
+		Time:        start,
+		RequestType: typeName(reflect.TypeOf(rq)),
+		Principal:   Principal(ctx),
+		Request:     rq,
+		Outcome:     auditOutcome(err),
+		Duration:    now().Sub(start),
+	}
+	if cmd != nil {
+		rec.HandlerType = typeName(reflect.TypeOf(unwrap(cmd)))
+	}
+	if r, ok := rq.(Redacter); ok {
+		rec.Request = r.Redacted()
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	auditSink.Audit(ctx, rec)
+}
+
+// auditOutcome classifies the specified error.  The outcome is determined
+// by the first error of a recognised type in the chain of wrapped errors.
+func auditOutcome(err error) AuditOutcome {
+	if err == nil {
+		return AuditSuccess
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch e.(type) {
+		case PanicError, *PanicError:
+			return AuditPanic
+		case NoCommandForRequestTypeError, *NoCommandForRequestTypeError, NoCommandForKeyError, *NoCommandForKeyError, AmbiguousCommandError, *AmbiguousCommandError:
+			return AuditNoCommand
+		case ResultTypeError, *ResultTypeError:
+			return AuditResultTypeError
+		case UnauthorizedError, *UnauthorizedError:
+			return AuditUnauthorized
+		case ForbiddenError, *ForbiddenError:
+			return AuditForbidden
+		case ValidationError, *ValidationError:
+			return AuditInvalid
+		}
+	}
+	return AuditError
+}
+
+// JSONLinesAuditSink is an AuditSink that writes each AuditRecord as a line of
+// JSON.
+type JSONLinesAuditSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONLinesAuditSink returns a JSONLinesAuditSink writing to the specified
+// writer.
+func NewJSONLinesAuditSink(w io.Writer) *JSONLinesAuditSink {
+	return &JSONLinesAuditSink{w: w, enc: json.NewEncoder(w)}
+}
+
+// OpenAuditLog returns a JSONLinesAuditSink appending to the file at the
+// specified path, creating the file if it does not exist.  Existing content of
+// the file is never modified.
+//
+// The sink should be closed when no longer required.
+func OpenAuditLog(path string) (*JSONLinesAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONLinesAuditSink(f), nil
+}
+
+// Audit satisfies the AuditSink interface, writing the record as a line of JSON.
+//
+// If the record cannot be written, the error is retained and may be obtained
+// using Err().  Any subsequent records will still be written (if possible).
+func (sink *JSONLinesAuditSink) Audit(_ context.Context, rec AuditRecord) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	if err := sink.enc.Encode(rec); err != nil && sink.err == nil {
+		sink.err = err
+	}
+}
+
+// Err returns the first error, if any, that occurred writing a record.
+func (sink *JSONLinesAuditSink) Err() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	return sink.err
+}
+
+// Close closes the underlying writer, if it implements io.Closer.
+func (sink *JSONLinesAuditSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	if c, ok := sink.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
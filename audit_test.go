@@ -0,0 +1,229 @@
+package mediator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// audittestsink is an AuditSink used for testing auditing.
+type audittestsink struct {
+	records []AuditRecord
+}
+
+func (sink *audittestsink) Audit(_ context.Context, rec AuditRecord) {
+	sink.records = append(sink.records, rec)
+}
+
+// audittestrequest is a request type implementing Redacter.
+type audittestrequest struct {
+	User     string
+	Password string
+}
+
+func (rq audittestrequest) Redacted() any {
+	rq.Password = "<redacted>"
+	return rq
+}
+
+func TestAudit(t *testing.T) {
+	// ARRANGE
+	ctx := ContextWithPrincipal(context.Background(), "user")
+
+	sink := &audittestsink{}
+	SetAuditSink(sink)
+	defer SetAuditSink(nil)
+
+	onow := now
+	defer func() { now = onow }()
+	start := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+	calls := 0
+	now = func() time.Time { calls++; return start.Add(time.Duration(calls-1) * time.Second) }
+
+	mock := MockCommand[audittestrequest, NoResultType]()
+	defer mock.Unregister()
+
+	// ACT
+	_, err := Execute(ctx, audittestrequest{User: "user", Password: "secret"}, NoResult)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// ASSERT
+	if len(sink.records) != 1 {
+		t.Fatalf("wanted 1 audit record, got %d", len(sink.records))
+	}
+	wanted := AuditRecord{
+		Time:        start,
+		RequestType: "github.com/blugnu/mediator.audittestrequest",
+		HandlerType: typeName(reflect.TypeOf(mock)),
+		Principal:   "user",
+		Request:     audittestrequest{User: "user", Password: "<redacted>"},
+		Outcome:     AuditSuccess,
+		Duration:    time.Second,
+	}
+	got := sink.records[0]
+	if !reflect.DeepEqual(wanted, got) {
+		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+	}
+}
+
+func TestAuditOutcome(t *testing.T) {
+	testcases := []struct {
+		name string
+		error
+		result AuditOutcome
+	}{
+		{name: "nil", error: nil, result: AuditSuccess},
+		{name: "no command", error: &NoCommandForRequestTypeError{}, result: AuditNoCommand},
+		{name: "ambiguous command", error: &AmbiguousCommandError{}, result: AuditNoCommand},
+		{name: "result type", error: &ResultTypeError{}, result: AuditResultTypeError},
+		{name: "unauthorized", error: UnauthorizedError{}, result: AuditUnauthorized},
+		{name: "forbidden", error: ForbiddenError{}, result: AuditForbidden},
+		{name: "validation", error: ValidationError{}, result: AuditInvalid},
+		{name: "wrapped validation", error: ValidationError{E: ForbiddenError{}}, result: AuditInvalid},
+		{name: "panic", error: &PanicError{Value: "panic"}, result: AuditPanic},
+		{name: "other", error: errors.New("error"), result: AuditError},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ACT
+			got := auditOutcome(tc.error)
+
+			// ASSERT
+			wanted := tc.result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+}
+
+func TestThatExecuteAuditsUnregisteredRequests(t *testing.T) {
+	// ARRANGE
+	sink := &audittestsink{}
+	SetAuditSink(sink)
+	defer SetAuditSink(nil)
+
+	// ACT
+	_, err := Execute(context.Background(), audittestrequest{}, NoResult)
+
+	// ASSERT
+	if len(sink.records) != 1 {
+		t.Fatalf("wanted 1 audit record, got %d", len(sink.records))
+	}
+	rec := sink.records[0]
+
+	t.Run("records outcome", func(t *testing.T) {
+		wanted := AuditNoCommand
+		got := rec.Outcome
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("records error", func(t *testing.T) {
+		wanted := err.Error()
+		got := rec.Error
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("has no handler type", func(t *testing.T) {
+		wanted := ""
+		got := rec.HandlerType
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestThatExecuteAuditsPanickingCommands(t *testing.T) {
+	// ARRANGE
+	sink := &audittestsink{}
+	SetAuditSink(sink)
+	defer SetAuditSink(nil)
+	registerPanicTestCommand(t)
+
+	// ACT
+	_ = executePanicTestRequest(context.Background())
+
+	// ASSERT
+	if len(sink.records) != 1 {
+		t.Fatalf("wanted 1 audit record, got %d", len(sink.records))
+	}
+	rec := sink.records[0]
+
+	wanted := []any{AuditPanic, "panic: command failed", "github.com/blugnu/mediator.panictestcmd"}
+	got := []any{rec.Outcome, rec.Error, rec.HandlerType}
+	if !reflect.DeepEqual(wanted, got) {
+		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+	}
+}
+
+func TestOpenAuditLog(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	write := func(rq string) {
+		sink, err := OpenAuditLog(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sink.Audit(ctx, AuditRecord{RequestType: "string", Request: rq, Outcome: AuditSuccess})
+		if err := sink.Err(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	// ACT
+	write("first")
+	write("second")
+
+	// ASSERT
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	got := []string{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		rec := AuditRecord{}
+		if err := json.Unmarshal(s.Bytes(), &rec); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, rec.Request.(string))
+	}
+
+	wanted := []string{"first", "second"}
+	if !reflect.DeepEqual(wanted, got) {
+		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+	}
+}
+
+func TestJSONLinesAuditSinkErr(t *testing.T) {
+	// ARRANGE
+	sink := NewJSONLinesAuditSink(&bytes.Buffer{})
+
+	// ACT
+	sink.Audit(context.Background(), AuditRecord{Request: func() {}})
+
+	// ASSERT
+	if sink.Err() == nil {
+		t.Error("wanted error, got nil")
+	}
+}
@@ -0,0 +1,170 @@
+package mediator
+
+import (
+	"context"
+	"reflect"
+)
+
+// Policy[TRequest] is the signature of a function that determines whether a
+// principal is permitted to execute a request of a particular type.  A policy
+// returns nil to permit the request or an error identifying why the request is
+// denied.
+type Policy[TRequest any] func(ctx context.Context, principal any, rq TRequest) error
+
+// policy holds a registered Policy[TRequest]; policies are held by reference
+// so that a specific registration can be identified when it is removed.
+type policy struct {
+	fn any
+}
+
+// principalKey is the context key for a principal added by ContextWithPrincipal.
+type principalKey struct{}
+
+var policies = map[reflect.Type][]*policy{}
+
+// principal provides the function used to obtain the principal associated
+// with a context.  The default function returns any principal added to the
+// context by ContextWithPrincipal.
+//
+// The function is a variable to support SetPrincipalFunc.
+var principal = defaultPrincipal
+
+func defaultPrincipal(ctx context.Context) any {
+	return ctx.Value(principalKey{})
+}
+
+// ContextWithPrincipal returns a copy of the specified context with the
+// specified principal.  The principal may be any value that identifies the
+// caller, e.g. a user or service account.
+//
+// This principal is used by the default principal function; if a custom
+// function is established using SetPrincipalFunc, the principal is
+// obtained by that function instead.
+func ContextWithPrincipal(ctx context.Context, p any) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// Principal returns the principal associated with the specified context, as
+// identified by the current principal function.  If there is no principal
+// associated with the context, nil is returned.
+func Principal(ctx context.Context) any {
+	return principal(ctx)
+}
+
+// SetPrincipalFunc establishes a function used to obtain the principal
+// associated with a context, enabling the mediator to use any existing
+// mechanism for identifying callers (e.g. claims from an authentication
+// token added to the context by http middleware).
+//
+// Specifying a nil function restores the default, which obtains any
+// principal added to the context by ContextWithPrincipal.
+func SetPrincipalFunc(fn func(context.Context) any) {
+	if fn == nil {
+		fn = defaultPrincipal
+	}
+	principal = fn
+}
+
+// RegisterPolicy[TRequest] registers a policy that must permit a request of
+// the specified type before it is passed to the command registered for that
+// type.  Any number of policies may be registered for a request type; all
+// must permit a request for it to be executed.  Policies are evaluated in the
+// order in which they are registered, before any Authorizer implemented by
+// the command itself.
+//
+// The function returns a function which removes the policy; this is typically
+// only required in tests.
+func RegisterPolicy[TRequest any](fn Policy[TRequest]) func() {
+	rqt := reflect.TypeOf(*new(TRequest))
+	p := &policy{fn: fn}
+
+	policies[rqt] = append(policies[rqt], p)
+
+	return func() {
+		ps := policies[rqt]
+		for i, each := range ps {
+			if each == p {
+				policies[rqt] = append(ps[:i:i], ps[i+1:]...)
+				break
+			}
+		}
+		if len(policies[rqt]) == 0 {
+			delete(policies, rqt)
+		}
+	}
+}
+
+// authorize applies any policies registered for the request type and any
+// Authorizer implemented by the command to the specified request.
+//
+// If authorization is not required (there are no policies and the command does
+// not implement Authorizer) then nil is returned.  Otherwise, if there is no
+// principal associated with the context an UnauthorizedError is returned.  Any
+// error returned by a policy or Authorizer is returned; if the error is not an
+// UnauthorizedError or ForbiddenError then it is wrapped in a ForbiddenError.
+//
+// With pointer equivalence (see SetPointerEquivalence) any policies registered
+// for the type to which a pointer request type points are also applied, to the
+// dereferenced request.
+func authorize[TRequest any](ctx context.Context, cmd any, rq TRequest) error {
+	ps, eps := policies[reflect.TypeOf(rq)], equivalentPolicies(rq)
+	az, isAuthorizer := cmd.(Authorizer[TRequest])
+	if !isAuthorizer {
+		az, isAuthorizer = asAdaptedAuthorizer[TRequest](cmd)
+	}
+	if len(ps) == 0 && len(eps) == 0 && !isAuthorizer {
+		return nil
+	}
+
+	p := Principal(ctx)
+	if p == nil {
+		return UnauthorizedError{E: ErrNoPrincipal}
+	}
+
+	for _, each := range ps {
+		if err := each.fn.(Policy[TRequest])(ctx, p, rq); err != nil {
+			return forbidden(err)
+		}
+	}
+
+	if len(eps) > 0 {
+		v := reflect.ValueOf(rq)
+		if v.IsNil() {
+			return ForbiddenError{E: ErrNilRequest}
+		}
+		in := []reflect.Value{reflect.ValueOf(&ctx).Elem(), reflect.ValueOf(&p).Elem(), v.Elem()}
+		for _, each := range eps {
+			if err, _ := reflect.ValueOf(each.fn).Call(in)[0].Interface().(error); err != nil {
+				return forbidden(err)
+			}
+		}
+	}
+
+	if isAuthorizer {
+		if err := az.Authorize(ctx, p, rq); err != nil {
+			return forbidden(err)
+		}
+	}
+
+	return nil
+}
+
+// equivalentPolicies returns the policies registered for the type to which the
+// type of the specified request points, if pointer equivalence is enabled.
+func equivalentPolicies(rq any) []*policy {
+	rqt := reflect.TypeOf(rq)
+	if !pointerEquivalence || rqt == nil || rqt.Kind() != reflect.Pointer {
+		return nil
+	}
+	return policies[rqt.Elem()]
+}
+
+// forbidden returns the specified error if it is an UnauthorizedError or a
+// ForbiddenError, otherwise the error is returned wrapped in a ForbiddenError.
+func forbidden(err error) error {
+	switch err.(type) {
+	case UnauthorizedError, *UnauthorizedError, ForbiddenError, *ForbiddenError:
+		return err
+	}
+	return ForbiddenError{E: err}
+}
@@ -0,0 +1,259 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// authorizationtestcmd is a command used for testing authorization.
+type authorizationtestcmd struct {
+	autherr    error
+	authorized []any
+	executed   bool
+}
+
+func (cmd *authorizationtestcmd) Authorize(_ context.Context, p any, _ float64) error {
+	cmd.authorized = append(cmd.authorized, p)
+	return cmd.autherr
+}
+
+func (cmd *authorizationtestcmd) Execute(context.Context, float64) (NoResultType, error) {
+	cmd.executed = true
+	return nil, nil
+}
+
+func TestPrincipal(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	t.Run("when no principal in context", func(t *testing.T) {
+		// ACT
+		got := Principal(ctx)
+
+		// ASSERT
+		if got != nil {
+			t.Errorf("\nwanted nil\ngot    %#v", got)
+		}
+	})
+
+	t.Run("when principal added to context", func(t *testing.T) {
+		// ACT
+		got := Principal(ContextWithPrincipal(ctx, "user"))
+
+		// ASSERT
+		wanted := "user"
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("with custom principal func", func(t *testing.T) {
+		// ARRANGE
+		SetPrincipalFunc(func(context.Context) any { return "custom" })
+		defer SetPrincipalFunc(nil)
+
+		// ACT
+		got := Principal(ContextWithPrincipal(ctx, "user"))
+
+		// ASSERT
+		wanted := "custom"
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestRegisterPolicy(t *testing.T) {
+	// ARRANGE
+	p1 := func(context.Context, any, float64) error { return nil }
+	p2 := func(context.Context, any, float64) error { return nil }
+
+	// ACT
+	unreg1 := RegisterPolicy(p1)
+	unreg2 := RegisterPolicy(p2)
+
+	// ASSERT
+	t.Run("adds policies to registry", func(t *testing.T) {
+		wanted := 2
+		got := len(policies[reflect.TypeOf(0.0)])
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("returns func which removes the policy", func(t *testing.T) {
+		// ACT
+		unreg1()
+
+		// ASSERT
+		wanted := 1
+		got := len(policies[reflect.TypeOf(0.0)])
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+
+		t.Run("removing request type when no policies remain", func(t *testing.T) {
+			// ACT
+			unreg2()
+
+			// ASSERT
+			_, got := policies[reflect.TypeOf(0.0)]
+			if got {
+				t.Error("request type remains in policy registry")
+			}
+		})
+	})
+}
+
+func TestAuthorization(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	pctx := ContextWithPrincipal(ctx, "user")
+
+	t.Run("when authorization is not required", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[float64, NoResultType]()
+		defer mock.Unregister()
+
+		// ACT
+		_, err := Execute(ctx, 1.0, NoResult)
+
+		// ASSERT
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("when there is no principal", func(t *testing.T) {
+		// ARRANGE
+		cmd := &authorizationtestcmd{}
+		unreg, _ := register(ctx, 0.0, cmd)
+		defer unreg()
+
+		// ACT
+		_, err := Execute(ctx, 1.0, NoResult)
+
+		// ASSERT
+		t.Run("returns UnauthorizedError", func(t *testing.T) {
+			wanted := UnauthorizedError{E: ErrNoPrincipal}
+			got := err
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("does not call the Authorizer", func(t *testing.T) {
+			if len(cmd.authorized) > 0 {
+				t.Error("Authorizer was called")
+			}
+		})
+	})
+
+	t.Run("when policy denies the request", func(t *testing.T) {
+		// ARRANGE
+		cmd := &authorizationtestcmd{}
+		unreg, _ := register(ctx, 0.0, cmd)
+		defer unreg()
+
+		perr := errors.New("denied")
+		unpol := RegisterPolicy(func(context.Context, any, float64) error { return perr })
+		defer unpol()
+
+		// ACT
+		_, err := Execute(pctx, 1.0, NoResult)
+
+		// ASSERT
+		t.Run("returns ForbiddenError", func(t *testing.T) {
+			wanted := ForbiddenError{E: perr}
+			got := err
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("does not call the Authorizer", func(t *testing.T) {
+			if len(cmd.authorized) > 0 {
+				t.Error("Authorizer was called")
+			}
+		})
+
+		t.Run("does not execute the command", func(t *testing.T) {
+			if cmd.executed {
+				t.Error("command was executed")
+			}
+		})
+	})
+
+	t.Run("when Authorizer denies the request", func(t *testing.T) {
+		denied := errors.New("denied")
+		forbidden := ForbiddenError{E: denied}
+		unauthorized := UnauthorizedError{E: errors.New("expired")}
+
+		testcases := []struct {
+			name   string
+			error  error
+			result error
+		}{
+			{name: "with error", error: denied, result: forbidden},
+			{name: "with ForbiddenError", error: forbidden, result: forbidden},
+			{name: "with UnauthorizedError", error: unauthorized, result: unauthorized},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				// ARRANGE
+				cmd := &authorizationtestcmd{autherr: tc.error}
+				unreg, _ := register(ctx, 0.0, cmd)
+				defer unreg()
+
+				// ACT
+				_, err := Execute(pctx, 1.0, NoResult)
+
+				// ASSERT
+				t.Run("passes principal to Authorizer", func(t *testing.T) {
+					wanted := []any{"user"}
+					got := cmd.authorized
+					if len(got) != 1 || got[0] != wanted[0] {
+						t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+					}
+				})
+
+				t.Run("returns expected error", func(t *testing.T) {
+					wanted := tc.result
+					got := err
+					if wanted != got {
+						t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+					}
+				})
+
+				t.Run("is not a ValidationError", func(t *testing.T) {
+					if errors.As(err, new(ValidationError)) {
+						t.Errorf("got ValidationError: %v", err)
+					}
+				})
+			})
+		}
+	})
+
+	t.Run("when authorized", func(t *testing.T) {
+		// ARRANGE
+		cmd := &authorizationtestcmd{}
+		unreg, _ := register(ctx, 0.0, cmd)
+		defer unreg()
+
+		unpol := RegisterPolicy(func(context.Context, any, float64) error { return nil })
+		defer unpol()
+
+		// ACT
+		_, err := Execute(pctx, 1.0, NoResult)
+
+		// ASSERT
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !cmd.executed {
+			t.Error("command was not executed")
+		}
+	})
+}
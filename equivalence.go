@@ -0,0 +1,49 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// ErrNilRequest is the error wrapped by the ValidationError returned when a
+// nil pointer request is executed by a command registered for the type to
+// which it points (see SetPointerEquivalence).  It is wrapped by a
+// ForbiddenError if policies registered for the type to which it points cannot
+// be applied to a nil pointer request.
+var ErrNilRequest = errors.New("nil request")
+
+// pointerEquivalence determines whether a command registered for a request
+// type also executes requests of a pointer to that type (see
+// SetPointerEquivalence).
+var pointerEquivalence bool
+
+// SetPointerEquivalence determines whether a command registered for a request
+// type T also executes requests of type *T, for which no command is
+// registered:
+//
+//	_ = mediator.RegisterCommand[CreateOrder.Request, *CreateOrder.Result](ctx, &CreateOrder.Command{})
+//	mediator.SetPointerEquivalence(true)
+//
+//	// executed by CreateOrder.Command
+//	result, err := mediator.Execute(ctx, &CreateOrder.Request{...}, new(*CreateOrder.Result))
+//
+// The request is dereferenced before it is authorized, validated and executed
+// by the command.  A nil pointer is rejected with a ValidationError wrapping
+// ErrNilRequest.  Any policies registered for T are also applied to requests
+// of type *T.
+//
+// Pointer equivalence is disabled by default.  Commands registered with a name
+// are not subject to pointer equivalence.
+func SetPointerEquivalence(enabled bool) {
+	pointerEquivalence = enabled
+}
+
+// lookupEquivalent returns the command registered for the type to which the
+// specified request type points, if pointer equivalence is enabled.
+func lookupEquivalent(ctx context.Context, rqt reflect.Type) (any, bool) {
+	if !pointerEquivalence || rqt == nil || rqt.Kind() != reflect.Pointer {
+		return nil, false
+	}
+	return lookup(ctx, rqt.Elem())
+}
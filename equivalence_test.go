@@ -0,0 +1,149 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// equivalencetestrequest is a request type used for testing pointer
+// equivalence.
+type equivalencetestrequest struct{ id int }
+
+// equivalencetestcmd is a command for equivalencetestrequest requests which
+// validates and authorizes requests.
+type equivalencetestcmd struct{}
+
+func (equivalencetestcmd) Execute(_ context.Context, rq equivalencetestrequest) (int, error) {
+	return rq.id, nil
+}
+
+func (equivalencetestcmd) Validate(_ context.Context, rq equivalencetestrequest) error {
+	if rq.id < 0 {
+		return errors.New("id must not be negative")
+	}
+	return nil
+}
+
+func (equivalencetestcmd) Authorize(_ context.Context, principal any, rq equivalencetestrequest) error {
+	if principal != "admin" && rq.id > 100 {
+		return errors.New("not permitted")
+	}
+	return nil
+}
+
+// enablePointerEquivalence enables pointer equivalence for the duration of a
+// test.
+func enablePointerEquivalence(t *testing.T) {
+	og := pointerEquivalence
+	SetPointerEquivalence(true)
+	t.Cleanup(func() { pointerEquivalence = og })
+}
+
+func TestPointerEquivalence(t *testing.T) {
+	// ARRANGE
+	ctx := ContextWithPrincipal(context.Background(), "user")
+	rqt := reflect.TypeOf(equivalencetestrequest{})
+	if err := RegisterCommand[equivalencetestrequest, int](ctx, equivalencetestcmd{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer delete(commands, rqt)
+
+	t.Run("when disabled", func(t *testing.T) {
+		// ACT
+		_, err := Execute(ctx, &equivalencetestrequest{id: 1}, new(int))
+
+		// ASSERT
+		wanted := "no command registered for requests of type: *github.com/blugnu/mediator.equivalencetestrequest (a command is registered for requests of type: github.com/blugnu/mediator.equivalencetestrequest)"
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when enabled", func(t *testing.T) {
+		// ARRANGE
+		enablePointerEquivalence(t)
+
+		t.Run("executes pointer request", func(t *testing.T) {
+			// ACT
+			result, err := Execute(ctx, &equivalencetestrequest{id: 42}, new(int))
+
+			// ASSERT
+			wanted := []any{42, nil}
+			got := []any{result, err}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		testcases := []struct {
+			name    string
+			request *equivalencetestrequest
+			target  any
+		}{
+			{name: "invalid request", request: &equivalencetestrequest{id: -1}, target: new(ValidationError)},
+			{name: "forbidden request", request: &equivalencetestrequest{id: 101}, target: new(ForbiddenError)},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				// ACT
+				_, err := Execute(ctx, tc.request, new(int))
+
+				// ASSERT
+				if !errors.As(err, tc.target) {
+					t.Errorf("\nwanted %T\ngot    %#v", tc.target, err)
+				}
+			})
+		}
+
+		t.Run("nil request", func(t *testing.T) {
+			// ACT
+			_, err := Execute[*equivalencetestrequest](ctx, nil, new(int))
+
+			// ASSERT
+			wanted := ValidationError{E: ErrNilRequest}
+			got := err
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("applies policies for value type", func(t *testing.T) {
+			// ARRANGE
+			denied := errors.New("denied")
+			defer RegisterPolicy(func(_ context.Context, _ any, rq equivalencetestrequest) error {
+				if rq.id == 13 {
+					return denied
+				}
+				return nil
+			})()
+
+			// ACT
+			_, err := Execute(ctx, &equivalencetestrequest{id: 13}, new(int))
+
+			// ASSERT
+			wanted := ForbiddenError{E: denied}
+			got := err
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("ResolveCommand", func(t *testing.T) {
+			// ACT
+			result, err := ResolveCommand(reflect.TypeOf(&equivalencetestrequest{}))
+
+			// ASSERT
+			wanted := []any{"github.com/blugnu/mediator.equivalencetestrequest", "github.com/blugnu/mediator.equivalencetestcmd", nil}
+			got := []any{result.RequestType, result.HandlerType, err}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+}
@@ -1,39 +1,67 @@
 package mediator
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
-// NoCommandForRequestTypeError is returned by Execute if there is no command
-// registered for the request and result type involved.
+// CommandAlreadyRegisteredError is returned when registering a command for a
+// request type (or interface) for which a command is already registered.
+// Types are identified by the full path of the package in which they are
+// declared:
+//
+//	"example.com/a.Command already registered for requests of type: example.com/a.Request"
 type CommandAlreadyRegisteredError struct {
-	command any
-	request any
+	command     any
+	request     any
+	requestType reflect.Type // the request type, if not the type of request (e.g. an interface)
 }
 
 func (e CommandAlreadyRegisteredError) Error() string {
-	return fmt.Sprintf("%T already registered for requests of type: %T", e.command, e.request)
+	return fmt.Sprintf("%s already registered for requests of type: %s", typeName(reflect.TypeOf(e.command)), typeName(e.rqt()))
 }
 
 func (e CommandAlreadyRegisteredError) Is(target error) bool {
 	if other, ok := target.(CommandAlreadyRegisteredError); ok {
-		return ok && reflect.TypeOf(other.request) == reflect.TypeOf(e.request)
+		return ok && other.rqt() == e.rqt()
 	}
 	if other, ok := target.(*CommandAlreadyRegisteredError); ok {
-		return ok && reflect.TypeOf(other.request) == reflect.TypeOf(e.request)
+		return ok && other.rqt() == e.rqt()
 	}
 	return false
 }
 
+// rqt returns the request type for which a command is already registered.
+func (e CommandAlreadyRegisteredError) rqt() reflect.Type {
+	if e.requestType != nil {
+		return e.requestType
+	}
+	return reflect.TypeOf(e.request)
+}
+
 // NoCommandForRequestTypeError is returned by Execute if there is no command
-// registered for the request and result type involved.
+// registered for the request and result type involved.  Types are identified
+// by the full path of the package in which they are declared.  If commands are
+// registered for types which the caller may have intended (see NearMisses),
+// the error identifies them:
+//
+//	"no command registered for requests of type: *example.com/a.Request (a command is registered for requests of type: example.com/a.Request)"
 type NoCommandForRequestTypeError struct {
-	request any
+	request    any
+	nearMisses []reflect.Type
 }
 
 func (e NoCommandForRequestTypeError) Error() string {
-	return fmt.Sprintf("no command registered for requests of type: %T", e.request)
+	s := "no command registered for requests of type: " + typeName(e.RequestType())
+	switch len(e.nearMisses) {
+	case 0:
+		return s
+	case 1:
+		return fmt.Sprintf("%s (a command is registered for requests of type: %s)", s, typeName(e.nearMisses[0]))
+	}
+	return fmt.Sprintf("%s (commands are registered for requests of types: %s)", s, typeNames(e.nearMisses))
 }
 
 func (e NoCommandForRequestTypeError) Is(target error) bool {
@@ -46,16 +74,42 @@ func (e NoCommandForRequestTypeError) Is(target error) bool {
 	return false
 }
 
+// RequestType returns the type of the request for which no command is
+// registered.
+func (e NoCommandForRequestTypeError) RequestType() reflect.Type {
+	return reflect.TypeOf(e.request)
+}
+
+// NearMisses returns the types, other than the request type, for which
+// commands are registered that the caller may have intended: types differing
+// from the request type only by indirection (e.g. a.Request for a *a.Request)
+// and types with the same name declared in other packages.
+func (e NoCommandForRequestTypeError) NearMisses() []reflect.Type {
+	return e.nearMisses
+}
+
 // ResultTypeError is returned if the command registered for the
 // specified request type does not return the result type expected
-// by the caller.
+// by the caller.  Types are identified by the full path of the package
+// in which they are declared:
+//
+//	"example.com/a.Command does not return *example.com/b.Result for requests of type: example.com/a.Request (returns: *example.com/a.Result)"
 type ResultTypeError struct {
-	command any
-	result  any
+	command    any
+	result     any
+	request    reflect.Type
+	resultType reflect.Type
 }
 
 func (e ResultTypeError) Error() string {
-	return fmt.Sprintf("%T does not return %T", e.command, e.result)
+	s := fmt.Sprintf("%s does not return %s", typeName(e.CommandType()), typeName(e.ResultType()))
+	if e.request != nil {
+		s += " for requests of type: " + typeName(e.request)
+	}
+	if rt := e.RegisteredResultType(); rt != nil {
+		s += " (returns: " + typeName(rt) + ")"
+	}
+	return s
 }
 
 func (e ResultTypeError) Is(target error) bool {
@@ -68,6 +122,38 @@ func (e ResultTypeError) Is(target error) bool {
 	return false
 }
 
+// CommandType returns the type of the command registered for the request type
+// (or the command wrapped by a registered wrapper, such as a spy).
+func (e ResultTypeError) CommandType() reflect.Type {
+	return reflect.TypeOf(unwrap(e.command))
+}
+
+// RequestType returns the type of the request, if known.
+func (e ResultTypeError) RequestType() reflect.Type {
+	return e.request
+}
+
+// ResultType returns the result type expected by the caller.
+func (e ResultTypeError) ResultType() reflect.Type {
+	if e.resultType != nil {
+		return e.resultType
+	}
+	return reflect.TypeOf(e.result)
+}
+
+// RegisteredResultType returns the result type returned by the command
+// registered for the request type, if it can be determined.
+func (e ResultTypeError) RegisteredResultType() reflect.Type {
+	if e.command == nil {
+		return nil
+	}
+	m, ok := reflect.TypeOf(e.command).MethodByName("Execute")
+	if !ok || m.Type.NumOut() == 0 {
+		return nil
+	}
+	return m.Type.Out(0)
+}
+
 // ValidationError is returned by a command when it is unable to
 // process a request due to the request itself being invalid.  The
 // ValidationError wraps a specific error that identifies the
@@ -85,3 +171,219 @@ func (e ValidationError) Error() string {
 func (e ValidationError) Unwrap() error {
 	return e.E
 }
+
+// PanicError is the error recorded by the mediator when a command panics,
+// e.g. in an AuditRecord, the statistics for the request type and the
+// ExecutionEvent passed to AfterExecute hooks.  The panic is not recovered:
+// once recorded, the panic is resumed with the original value.
+//
+//	"panic: <value>"
+type PanicError struct {
+	Value any    // the value passed to panic()
+	Stack []byte // the stack trace of the goroutine at the time of the panic
+}
+
+func (e PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic(), if it is an error.
+func (e PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ErrNoPrincipal is the error wrapped by an UnauthorizedError returned
+// when authorization is required for a request but there is no principal
+// associated with the context.
+var ErrNoPrincipal = errors.New("no principal")
+
+// UnauthorizedError is returned by Execute when authorization is required
+// for a request but the caller has not been identified.  The
+// UnauthorizedError wraps a specific error that identifies the problem;
+// when no principal is associated with the context this is ErrNoPrincipal.
+//
+//	"unauthorized: <specific error>"
+type UnauthorizedError struct {
+	E error
+}
+
+func (e UnauthorizedError) Error() string {
+	return fmt.Sprintf("unauthorized: %v", e.E)
+}
+
+func (e UnauthorizedError) Unwrap() error {
+	return e.E
+}
+
+// ForbiddenError is returned by Execute when the principal associated
+// with the context is not permitted to execute a request.  The
+// ForbiddenError wraps the specific error returned by the policy or
+// Authorizer that denied the request.
+//
+//	"forbidden: <specific error>"
+type ForbiddenError struct {
+	E error
+}
+
+func (e ForbiddenError) Error() string {
+	return fmt.Sprintf("forbidden: %v", e.E)
+}
+
+func (e ForbiddenError) Unwrap() error {
+	return e.E
+}
+
+// CommandCycleError is returned by Execute when a request is executed by a
+// command which is already executing a request of the same type (directly or
+// indirectly), which would otherwise recurse indefinitely.  The error
+// identifies the chain of request types involved:
+//
+//	"command cycle detected: example.com/a.Request -> example.com/b.Request -> example.com/a.Request"
+type CommandCycleError struct {
+	chain []reflect.Type
+}
+
+func (e CommandCycleError) Error() string {
+	return fmt.Sprintf("command cycle detected: %s", formatChain(e.chain))
+}
+
+func (e CommandCycleError) Is(target error) bool {
+	switch target.(type) {
+	case CommandCycleError, *CommandCycleError:
+		return true
+	}
+	return false
+}
+
+// Chain returns the request types in the cycle, outermost first.  The last
+// request type is the request type which was not executed.
+func (e CommandCycleError) Chain() []reflect.Type {
+	return e.chain
+}
+
+// ExecutionDepthError is returned by Execute when executing a request would
+// exceed the maximum depth of nested requests (see SetMaxExecutionDepth).
+//
+//	"maximum execution depth (32) exceeded: example.com/a.Request -> example.com/b.Request -> ..."
+type ExecutionDepthError struct {
+	max   int
+	chain []reflect.Type
+}
+
+func (e ExecutionDepthError) Error() string {
+	return fmt.Sprintf("maximum execution depth (%d) exceeded: %s", e.max, formatChain(e.chain))
+}
+
+func (e ExecutionDepthError) Is(target error) bool {
+	switch target.(type) {
+	case ExecutionDepthError, *ExecutionDepthError:
+		return true
+	}
+	return false
+}
+
+// Chain returns the request types in the chain of nested requests, outermost
+// first.  The last request type is the request type which was not executed.
+func (e ExecutionDepthError) Chain() []reflect.Type {
+	return e.chain
+}
+
+// TypeName returns the name by which the mediator identifies the specified
+// type (e.g. in an AuditRecord, RegistrationInfo or error), qualified by the
+// full path of the package in which it is declared.
+func TypeName(t reflect.Type) string {
+	return typeName(t)
+}
+
+// typeName returns the name of the specified type, qualified by the full
+// path of the package in which it is declared.
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	if t.Kind() == reflect.Pointer {
+		return "*" + typeName(t.Elem())
+	}
+	return t.String()
+}
+
+// typeNames returns a comma-separated list of the names of the specified types.
+func typeNames(types []reflect.Type) string {
+	s := make([]string, len(types))
+	for i, t := range types {
+		s[i] = typeName(t)
+	}
+	return strings.Join(s, ", ")
+}
+
+// formatChain returns a representation of a chain of request types.
+func formatChain(chain []reflect.Type) string {
+	s := make([]string, len(chain))
+	for i, t := range chain {
+		s[i] = typeName(t)
+	}
+	return strings.Join(s, " -> ")
+}
+
+// NoCommandForKeyError is returned by Execute (or ExecuteNamed) if there is
+// no command registered for the request type with the name specified or
+// selected (see RegisterNamedCommand).
+//
+//	"no command registered for requests of type: example.com/a.Request with key: \"paypal\""
+type NoCommandForKeyError struct {
+	request any
+	key     string
+}
+
+func (e NoCommandForKeyError) Error() string {
+	return fmt.Sprintf("no command registered for requests of type: %s with key: %q", typeName(reflect.TypeOf(e.request)), e.key)
+}
+
+func (e NoCommandForKeyError) Is(target error) bool {
+	if other, ok := target.(NoCommandForKeyError); ok {
+		return reflect.TypeOf(other.request) == reflect.TypeOf(e.request) && other.key == e.key
+	}
+	if other, ok := target.(*NoCommandForKeyError); ok {
+		return reflect.TypeOf(other.request) == reflect.TypeOf(e.request) && other.key == e.key
+	}
+	return false
+}
+
+// Key returns the name of the command which is not registered.
+func (e NoCommandForKeyError) Key() string {
+	return e.key
+}
+
+// AmbiguousCommandError is returned by RegisterInterfaceCommand if a command
+// is registered with the same priority for an interface which implements (or
+// is implemented by) the interface being registered.  It is returned by
+// Execute if a request type implements more than one interface for which
+// commands are registered with the same, highest priority.
+//
+//	"ambiguous command for requests of type: example.com/a.Request: example.com/a.Auditable, example.com/a.Traceable registered with equal priority"
+type AmbiguousCommandError struct {
+	requestType reflect.Type
+	interfaces  []reflect.Type
+}
+
+func (e AmbiguousCommandError) Error() string {
+	return fmt.Sprintf("ambiguous command for requests of type: %s: %s registered with equal priority", typeName(e.requestType), typeNames(e.interfaces))
+}
+
+func (e AmbiguousCommandError) Is(target error) bool {
+	switch target.(type) {
+	case AmbiguousCommandError, *AmbiguousCommandError:
+		return true
+	}
+	return false
+}
+
+// Interfaces returns the interfaces for which commands are registered with
+// equal priority.
+func (e AmbiguousCommandError) Interfaces() []reflect.Type {
+	return e.interfaces
+}
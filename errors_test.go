@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -18,9 +19,9 @@ func Test_CommandAlreadyRegisteredError(t *testing.T) {
 			request any
 			result  string
 		}{
-			{request: "", result: "mediator.errorstestcmd already registered for requests of type: string"},
-			{request: true, result: "mediator.errorstestcmd already registered for requests of type: bool"},
-			{request: 42, result: "mediator.errorstestcmd already registered for requests of type: int"},
+			{request: "", result: "github.com/blugnu/mediator.errorstestcmd already registered for requests of type: string"},
+			{request: true, result: "github.com/blugnu/mediator.errorstestcmd already registered for requests of type: bool"},
+			{request: 42, result: "github.com/blugnu/mediator.errorstestcmd already registered for requests of type: int"},
 		}
 		for _, tc := range testcases {
 			t.Run(fmt.Sprintf("%T", tc.request), func(t *testing.T) {
@@ -75,17 +76,21 @@ func Test_CommandAlreadyRegisteredError(t *testing.T) {
 func Test_NoCommandForRequestTypeError(t *testing.T) {
 	t.Run("Error()", func(t *testing.T) {
 		testcases := []struct {
-			request any
-			result  string
+			request    any
+			nearMisses []reflect.Type
+			result     string
 		}{
 			{request: "", result: "no command registered for requests of type: string"},
 			{request: true, result: "no command registered for requests of type: bool"},
 			{request: 42, result: "no command registered for requests of type: int"},
+			{request: errorstestcmd{}, result: "no command registered for requests of type: github.com/blugnu/mediator.errorstestcmd"},
+			{request: new(int), nearMisses: []reflect.Type{reflect.TypeOf(0)}, result: "no command registered for requests of type: *int (a command is registered for requests of type: int)"},
+			{request: &errorstestcmd{}, nearMisses: []reflect.Type{reflect.TypeOf(errorstestcmd{}), reflect.TypeOf(registrationtestcmd{})}, result: "no command registered for requests of type: *github.com/blugnu/mediator.errorstestcmd (commands are registered for requests of types: github.com/blugnu/mediator.errorstestcmd, github.com/blugnu/mediator.registrationtestcmd)"},
 		}
 		for _, tc := range testcases {
 			t.Run(fmt.Sprintf("%T", tc.request), func(t *testing.T) {
 				// ARRANGE
-				sut := &NoCommandForRequestTypeError{tc.request}
+				sut := &NoCommandForRequestTypeError{request: tc.request, nearMisses: tc.nearMisses}
 
 				// ACT
 				s := sut.Error()
@@ -130,6 +135,21 @@ func Test_NoCommandForRequestTypeError(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("accessors", func(t *testing.T) {
+		// ARRANGE
+		sut := NoCommandForRequestTypeError{request: new(int), nearMisses: []reflect.Type{reflect.TypeOf(0)}}
+
+		// ACT
+		result := []any{sut.RequestType(), sut.NearMisses()}
+
+		// ASSERT
+		wanted := []any{reflect.TypeOf(new(int)), []reflect.Type{reflect.TypeOf(0)}}
+		got := result
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
 }
 
 func Test_ResultTypeError(t *testing.T) {
@@ -141,10 +161,46 @@ func Test_ResultTypeError(t *testing.T) {
 	sut := &ResultTypeError{command: mock, result: "command result"}
 
 	t.Run("Error()", func(t *testing.T) {
+		testcases := []struct {
+			name   string
+			sut    ResultTypeError
+			result string
+		}{
+			{name: "with result",
+				sut:    ResultTypeError{command: errorstestcmd{}, result: ""},
+				result: "github.com/blugnu/mediator.errorstestcmd does not return string (returns: github.com/blugnu/mediator.NoResultType)",
+			},
+			{name: "with request and result types",
+				sut:    ResultTypeError{command: errorstestcmd{}, request: reflect.TypeOf(0), resultType: reflect.TypeOf((*error)(nil)).Elem()},
+				result: "github.com/blugnu/mediator.errorstestcmd does not return error for requests of type: int (returns: github.com/blugnu/mediator.NoResultType)",
+			},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				// ACT
+				s := tc.sut.Error()
+
+				// ASSERT
+				wanted := tc.result
+				got := s
+				if wanted != got {
+					t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+				}
+			})
+		}
+	})
+
+	t.Run("accessors", func(t *testing.T) {
+		// ARRANGE
+		sut := ResultTypeError{command: errorstestcmd{}, result: "", request: reflect.TypeOf(0)}
+
+		// ACT
+		result := []reflect.Type{sut.CommandType(), sut.RequestType(), sut.ResultType(), sut.RegisteredResultType()}
+
 		// ASSERT
-		wanted := fmt.Sprintf("%T does not return string", mock)
-		got := sut.Error()
-		if wanted != got {
+		wanted := []reflect.Type{reflect.TypeOf(errorstestcmd{}), reflect.TypeOf(0), reflect.TypeOf(""), reflect.TypeOf((*NoResultType)(nil)).Elem()}
+		got := result
+		if !reflect.DeepEqual(wanted, got) {
 			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
 		}
 	})
@@ -178,6 +234,27 @@ func Test_ResultTypeError(t *testing.T) {
 	})
 }
 
+func Test_PanicError(t *testing.T) {
+	// ARRANGE
+	perr := errors.New("panic error")
+
+	t.Run("Error()", func(t *testing.T) {
+		wanted := "panic: command failed"
+		got := PanicError{Value: "command failed"}.Error()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Unwrap()", func(t *testing.T) {
+		wanted := []error{perr, nil}
+		got := []error{PanicError{Value: perr}.Unwrap(), PanicError{Value: "command failed"}.Unwrap()}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
 func Test_ValidationError(t *testing.T) {
 	// ARRANGE
 	e := errors.New("inner error")
@@ -205,3 +282,190 @@ func Test_ValidationError(t *testing.T) {
 		}
 	})
 }
+
+func Test_UnauthorizedError(t *testing.T) {
+	// ARRANGE
+	e := errors.New("inner error")
+
+	// ACT
+	sut := &UnauthorizedError{E: e}
+
+	// ASSERT
+	t.Run("Error()", func(t *testing.T) {
+		wanted := fmt.Sprintf("unauthorized: %v", e)
+		got := sut.Error()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Unwrap()", func(t *testing.T) {
+		wanted := e
+		got := sut.Unwrap()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func Test_ForbiddenError(t *testing.T) {
+	// ARRANGE
+	e := errors.New("inner error")
+
+	// ACT
+	sut := &ForbiddenError{E: e}
+
+	// ASSERT
+	t.Run("Error()", func(t *testing.T) {
+		wanted := fmt.Sprintf("forbidden: %v", e)
+		got := sut.Error()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Unwrap()", func(t *testing.T) {
+		wanted := e
+		got := sut.Unwrap()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func Test_CommandCycleError(t *testing.T) {
+	// ARRANGE
+	cmd := reflect.TypeOf(errorstestcmd{})
+	sut := CommandCycleError{chain: []reflect.Type{cmd, reflect.TypeOf(""), cmd}}
+
+	t.Run("Error()", func(t *testing.T) {
+		wanted := "command cycle detected: github.com/blugnu/mediator.errorstestcmd -> string -> github.com/blugnu/mediator.errorstestcmd"
+		got := sut.Error()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Is(target)", func(t *testing.T) {
+		wanted := []bool{true, true, false}
+		got := []bool{
+			errors.Is(sut, CommandCycleError{}),
+			errors.Is(sut, &CommandCycleError{}),
+			errors.Is(sut, ExecutionDepthError{}),
+		}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func Test_ExecutionDepthError(t *testing.T) {
+	// ARRANGE
+	sut := ExecutionDepthError{max: 1, chain: []reflect.Type{reflect.TypeOf(errorstestcmd{}), reflect.TypeOf(&errorstestcmd{})}}
+
+	t.Run("Error()", func(t *testing.T) {
+		wanted := "maximum execution depth (1) exceeded: github.com/blugnu/mediator.errorstestcmd -> *github.com/blugnu/mediator.errorstestcmd"
+		got := sut.Error()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Is(target)", func(t *testing.T) {
+		wanted := []bool{true, true, false}
+		got := []bool{
+			errors.Is(sut, ExecutionDepthError{}),
+			errors.Is(sut, &ExecutionDepthError{}),
+			errors.Is(sut, CommandCycleError{}),
+		}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func Test_NoCommandForKeyError(t *testing.T) {
+	// ARRANGE
+	sut := NoCommandForKeyError{request: 0, key: "key"}
+
+	t.Run("Error()", func(t *testing.T) {
+		sut := NoCommandForKeyError{request: &errorstestcmd{}, key: "key"}
+		wanted := "no command registered for requests of type: *github.com/blugnu/mediator.errorstestcmd with key: \"key\""
+		got := sut.Error()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Is(target)", func(t *testing.T) {
+		wanted := []bool{true, true, false, false}
+		got := []bool{
+			errors.Is(sut, NoCommandForKeyError{request: 0, key: "key"}),
+			errors.Is(sut, &NoCommandForKeyError{request: 0, key: "key"}),
+			errors.Is(sut, NoCommandForKeyError{request: 0, key: "other"}),
+			errors.Is(sut, NoCommandForKeyError{request: "", key: "key"}),
+		}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func Test_AmbiguousCommandError(t *testing.T) {
+	// ARRANGE
+	a, b := reflect.TypeOf((*fmt.Stringer)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()
+	sut := AmbiguousCommandError{requestType: reflect.TypeOf(errorstestcmd{}), interfaces: []reflect.Type{a, b}}
+
+	t.Run("Error()", func(t *testing.T) {
+		wanted := "ambiguous command for requests of type: github.com/blugnu/mediator.errorstestcmd: fmt.Stringer, error registered with equal priority"
+		got := sut.Error()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Is(target)", func(t *testing.T) {
+		wanted := []bool{true, true, false}
+		got := []bool{
+			errors.Is(sut, AmbiguousCommandError{}),
+			errors.Is(sut, &AmbiguousCommandError{}),
+			errors.Is(sut, CommandCycleError{}),
+		}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Interfaces()", func(t *testing.T) {
+		wanted := []reflect.Type{a, b}
+		got := sut.Interfaces()
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestTypeName(t *testing.T) {
+	testcases := []struct {
+		value  any
+		result string
+	}{
+		{value: nil, result: "<nil>"},
+		{value: 1, result: "int"},
+		{value: []string{}, result: "[]string"},
+		{value: errorstestcmd{}, result: "github.com/blugnu/mediator.errorstestcmd"},
+		{value: &errorstestcmd{}, result: "*github.com/blugnu/mediator.errorstestcmd"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.result, func(t *testing.T) {
+			// ACT
+			got := TypeName(reflect.TypeOf(tc.value))
+
+			// ASSERT
+			wanted := tc.result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+}
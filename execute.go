@@ -3,6 +3,8 @@ package mediator
 import (
 	"context"
 	"reflect"
+	"runtime/debug"
+	"time"
 )
 
 // Execute sends the specified request to the registered command for the
@@ -33,33 +35,151 @@ import (
 //	// call the Bar.Request command which returns only an error
 //	_, err := mediator.Execute(ctx, Bar.Request{}, mediator.NoResult)
 //
+// If any policies are registered for the request type or the command
+// implements Authorizer, the request is authorized before it is validated.
+// If there is no principal associated with the context an UnauthorizedError
+// is returned; if a policy or the Authorizer denies the request, the error
+// returned will be a ForbiddenError wrapping the error.
+//
 // If the command implements Validator and the validator returns an error,
 // then the command Execute() function is not called and the error returned
 // will be a ValidationError wrapping the error.
+//
+// If the command implements Transactional, the request is validated and
+// executed in a unit of work, joining any unit of work already begun by
+// the caller.
+//
+// If an AuditSink has been established, an AuditRecord is sent to the sink
+// recording the outcome of every request.
+//
+// Statistics for every request are recorded, and may be obtained using Stats().
+//
+// Any hooks established using AddHooks are called at each stage of execution.
+//
+// The context passed to the command (and any hooks) carries the Metadata of
+// the request, which may be obtained using RequestMetadata().
+//
+// If the command (or its Authorizer or Validator) panics, the panic is
+// recorded as a PanicError in the audit trail, statistics, hooks and any
+// CallTree before the panic is resumed; the mediator does not recover it.
+//
+// If the request type is already being executed in the chain of nested
+// requests leading to the call (i.e. a command executes a request which,
+// directly or indirectly, executes a request of the same type), then a
+// CommandCycleError is returned.  If the maximum depth of nested requests
+// would be exceeded, an ExecutionDepthError is returned.  The rejection of a
+// request is reported to any OnRejected hook.
+//
+// If a command selector is established for the request type (see
+// SetCommandSelector), the selector identifies the named command which
+// executes the request (see RegisterNamedCommand).
 func Execute[TRequest any, TResult any](ctx context.Context, req TRequest, resultHint *TResult) (TResult, error) {
+	return execute[TRequest, TResult](ctx, req, nil)
+}
+
+// execute executes a request using the command registered with the specified
+// name, or (if no name is specified) the name identified by any selector for
+// the request type or the command registered for the request type.
+func execute[TRequest any, TResult any](ctx context.Context, req TRequest, name *string) (result TResult, err error) {
 	// create a zero-value result for use in error conditions
 	z := *new(TResult)
 
+	// attach the metadata of the request to the context
+	ctx = withRequestMetadata(ctx)
+
+	rqt := reflect.TypeOf(req)
+
+	// record execution statistics
+	done := track(rqt)
+	defer func() { done(err) }()
+
+	// record the outcome in the audit trail, if required
+	var reg any
+	if auditSink != nil {
+		start := now()
+		defer func() { audit(ctx, start, req, reg, err) }()
+	}
+
+	// detect cycles and enforce the maximum depth of nested requests
+	ctx, complete, err := enter(ctx, rqt)
+	if err != nil {
+		fireHook(ctx, onRejected, ExecutionEvent{RequestType: rqt, Request: req, Err: err})
+		return z, err
+	}
+	defer func() { complete(err) }()
+
 	// identify the command registration for the request type
-	reg, ok := commands[reflect.TypeOf(req)]
-	if !ok {
-		return z, &NoCommandForRequestTypeError{req}
+	reg, err = resolve[TRequest, TResult](ctx, req, name)
+	if err != nil {
+		fireHook(ctx, onNoCommand, ExecutionEvent{RequestType: rqt, Request: req, Err: err})
+		return z, err
 	}
 
 	// check that registered command returns the result type expected by the caller
 	cmd, ok := reg.(CommandHandler[TRequest, TResult])
 	if !ok {
-		return z, &ResultTypeError{command: reg, result: z}
+		fireHook(ctx, onResultTypeMismatch, ExecutionEvent{RequestType: rqt, Request: req, Handler: unwrap(reg)})
+		return z, &ResultTypeError{command: reg, result: z, request: rqt, resultType: reflect.TypeOf(&z).Elem()}
+	}
+
+	// call any BeforeExecute and AfterExecute hooks
+	if hasHooks() {
+		event := ExecutionEvent{RequestType: rqt, Request: req, Handler: unwrap(reg)}
+		fireHook(ctx, beforeExecute, event)
+
+		start := time.Now()
+		defer func() {
+			event.Result, event.Err, event.Duration = result, err, time.Since(start)
+			fireHook(ctx, afterExecute, event)
+		}()
+	}
+
+	// record any panic in the error observed by the deferred functions above,
+	// before resuming the panic
+	defer recoverPanic(&err)
+
+	// reject any request which cannot be executed by an adapted command
+	if rc, ok := reg.(requestChecker); ok {
+		if err := rc.checkRequest(req); err != nil {
+			return z, err
+		}
 	}
 
+	// apply any policies and call the Authorizer, if implemented
+	if err := authorize(ctx, unwrap(reg), req); err != nil {
+		return z, err
+	}
+
+	// validate and execute the request, in a unit of work if required
+	return inUnitOfWork(ctx, unwrap(reg), func(ctx context.Context) (TResult, error) {
+		return validateAndExecute(ctx, cmd, req)
+	})
+}
+
+// validateAndExecute validates the specified request, if the command implements
+// Validator, and executes it, returning the result.
+func validateAndExecute[TRequest any, TResult any](ctx context.Context, cmd CommandHandler[TRequest, TResult], req TRequest) (TResult, error) {
 	// call the Validator, if implemented
-	if validator, ok := reg.(Validator[TRequest]); ok {
+	if validator, ok := cmd.(Validator[TRequest]); ok {
 		err := validate(validator, ctx, req)
+		fireHook(ctx, afterValidate, ExecutionEvent{RequestType: reflect.TypeOf(req), Request: req, Handler: unwrap(cmd), Err: err})
 		if err != nil {
-			return z, err
+			return *new(TResult), err
 		}
 	}
 
 	// call the command and return the result
 	return cmd.Execute(ctx, req)
 }
+
+// recoverPanic records a panic in progress as a PanicError in the specified
+// error, so that the panic is observed by the audit trail, statistics, hooks
+// and call trees, before resuming the panic with the original value.
+//
+// recoverPanic must be deferred after any function observing the error.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Value: r, Stack: debug.Stack()}
+		panic(r)
+	}
+}
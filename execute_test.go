@@ -3,6 +3,7 @@ package mediator
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -40,7 +41,7 @@ func TestThatHandlerReturnsExpectedErrorWhenHandlerIsNotRegistered(t *testing.T)
 	_, err := Execute(context.Background(), "request", NoResult)
 
 	// ASSERT
-	wanted := &NoCommandForRequestTypeError{"request"}
+	wanted := &NoCommandForRequestTypeError{request: "request"}
 	got := err
 	if !errors.Is(got, wanted) {
 		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
@@ -56,7 +57,7 @@ func TestThatHandlerReturnsExpectedErrorWhenHandlerResultIsWrongType(t *testing.
 	_, err := Execute(context.Background(), "request", NoResult)
 
 	// ASSERT
-	wanted := &ResultTypeError{mock, *new(NoResultType)}
+	wanted := &ResultTypeError{command: mock, result: *new(NoResultType)}
 	got := err
 	if !errors.Is(got, wanted) {
 		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
@@ -134,3 +135,44 @@ func TestThatHandlerResultIsReturnedInResultParam(t *testing.T) {
 		t.Errorf("wanted %q, got %q", wanted, got)
 	}
 }
+
+// panictestrequest is a request type executed by panictestcmd, which panics.
+type panictestrequest struct{}
+
+type panictestcmd struct{}
+
+func (panictestcmd) Execute(context.Context, panictestrequest) (NoResultType, error) {
+	panic("command failed")
+}
+
+// registerPanicTestCommand registers panictestcmd for the duration of a test.
+func registerPanicTestCommand(t *testing.T) {
+	t.Helper()
+	if err := RegisterCommand[panictestrequest, NoResultType](context.Background(), panictestcmd{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { delete(commands, reflect.TypeOf(panictestrequest{})) })
+}
+
+// executePanicTestRequest executes a panictestrequest, returning the value
+// with which the execution panicked.
+func executePanicTestRequest(ctx context.Context) (r any) {
+	defer func() { r = recover() }()
+	_, _ = Execute(ctx, panictestrequest{}, NoResult)
+	return nil
+}
+
+func TestThatExecuteResumesCommandPanics(t *testing.T) {
+	// ARRANGE
+	registerPanicTestCommand(t)
+
+	// ACT
+	result := executePanicTestRequest(context.Background())
+
+	// ASSERT
+	wanted := "command failed"
+	got := result
+	if wanted != got {
+		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+	}
+}
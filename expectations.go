@@ -0,0 +1,356 @@
+package mediator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Matcher[TRequest] is the interface implemented by a matcher of requests,
+// used to establish expectations on a mock command.
+type Matcher[TRequest any] interface {
+	// Match returns true if the request satisfies the matcher.
+	Match(TRequest) bool
+
+	// String describes the matcher.
+	String() string
+}
+
+// differ is an optional interface implemented by a Matcher to describe the
+// differences between a request and the requests matched by the matcher.
+type differ[TRequest any] interface {
+	diff(TRequest) []string
+}
+
+// equalMatcher[TRequest] matches requests equal to a specific request.
+type equalMatcher[TRequest any] struct {
+	request TRequest
+}
+
+// Equal[TRequest] returns a Matcher which matches requests that are deeply
+// equal to the specified request.
+func Equal[TRequest any](rq TRequest) Matcher[TRequest] {
+	return equalMatcher[TRequest]{request: rq}
+}
+
+func (m equalMatcher[TRequest]) Match(rq TRequest) bool {
+	return reflect.DeepEqual(m.request, rq)
+}
+
+func (m equalMatcher[TRequest]) String() string {
+	return fmt.Sprintf("Equal(%+v)", m.request)
+}
+
+func (m equalMatcher[TRequest]) diff(rq TRequest) []string {
+	return diff(reflect.ValueOf(m.request), reflect.ValueOf(rq), "", false)
+}
+
+// partialMatcher[TRequest] matches requests with fields equal to the non-zero
+// fields of a specific request.
+type partialMatcher[TRequest any] struct {
+	request TRequest
+}
+
+// Partial[TRequest] returns a Matcher which matches requests having fields
+// equal to all of the non-zero fields of the specified request; zero-value
+// fields in the specified request are ignored.  Nested structs are matched in
+// the same way.
+//
+// If the request type is not a struct, requests are matched if they are
+// deeply equal to the specified request.
+func Partial[TRequest any](rq TRequest) Matcher[TRequest] {
+	return partialMatcher[TRequest]{request: rq}
+}
+
+func (m partialMatcher[TRequest]) Match(rq TRequest) bool {
+	return len(m.diff(rq)) == 0
+}
+
+func (m partialMatcher[TRequest]) String() string {
+	return fmt.Sprintf("Partial(%+v)", m.request)
+}
+
+func (m partialMatcher[TRequest]) diff(rq TRequest) []string {
+	return diff(reflect.ValueOf(m.request), reflect.ValueOf(rq), "", true)
+}
+
+// funcMatcher[TRequest] matches requests satisfying a predicate function.
+type funcMatcher[TRequest any] struct {
+	description string
+	fn          func(TRequest) bool
+}
+
+// Matching[TRequest] returns a Matcher which matches requests for which the
+// specified predicate returns true.  The description describes the matcher
+// in any report of unmet expectations or unexpected requests.
+func Matching[TRequest any](description string, fn func(TRequest) bool) Matcher[TRequest] {
+	return funcMatcher[TRequest]{description: description, fn: fn}
+}
+
+func (m funcMatcher[TRequest]) Match(rq TRequest) bool {
+	return m.fn(rq)
+}
+
+func (m funcMatcher[TRequest]) String() string {
+	return fmt.Sprintf("Matching(%s)", m.description)
+}
+
+// anyMatcher[TRequest] matches all requests.
+type anyMatcher[TRequest any] struct{}
+
+// Any[TRequest] returns a Matcher which matches any request.
+func Any[TRequest any]() Matcher[TRequest] {
+	return anyMatcher[TRequest]{}
+}
+
+func (anyMatcher[TRequest]) Match(TRequest) bool { return true }
+func (anyMatcher[TRequest]) String() string      { return "Any()" }
+
+// diff returns a description of each difference between a wanted and a got
+// value, with each difference identified by the path to the field involved.
+// If partial is true, zero-value fields of the wanted value are ignored.
+func diff(wanted, got reflect.Value, path string, partial bool) []string {
+	if partial && (!wanted.IsValid() || wanted.IsZero()) {
+		return nil
+	}
+	if !wanted.IsValid() || !got.IsValid() || wanted.Type() != got.Type() {
+		if valuesEqual(wanted, got) {
+			return nil
+		}
+		return []string{difference(path, wanted, got)}
+	}
+
+	if wanted.Kind() == reflect.Struct {
+		result := []string{}
+		for i := 0; i < wanted.NumField(); i++ {
+			name := wanted.Type().Field(i).Name
+			if path != "" {
+				name = path + "." + name
+			}
+			result = append(result, diff(wanted.Field(i), got.Field(i), name, partial)...)
+		}
+		return result
+	}
+
+	if valuesEqual(wanted, got) {
+		return nil
+	}
+	return []string{difference(path, wanted, got)}
+}
+
+// valuesEqual returns true if the specified values are deeply equal.  Values
+// which cannot be obtained as an interface (e.g. unexported fields) are
+// compared by their formatted representations.
+func valuesEqual(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.CanInterface() && b.CanInterface() {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+	return fmt.Sprintf("%#v", a) == fmt.Sprintf("%#v", b)
+}
+
+// difference describes a difference between values at a path.
+func difference(path string, wanted, got reflect.Value) string {
+	if path == "" {
+		return fmt.Sprintf("wanted %#v, got %#v", wanted, got)
+	}
+	return fmt.Sprintf("%s: wanted %#v, got %#v", path, wanted, got)
+}
+
+// response[TResult] is a result and error returned by a mock command.
+type response[TResult any] struct {
+	result TResult
+	err    error
+}
+
+// Expectation[TRequest, TResult] is an expectation established on a mock
+// command for requests satisfying a matcher.
+type Expectation[TRequest any, TResult any] struct {
+	mock      *Mock[TRequest, TResult]
+	matcher   Matcher[TRequest]
+	responses []response[TResult]
+	times     int // the number of calls expected; -1 if at least one call is expected
+	calls     int
+}
+
+// Return adds a response to the expectation.  If Return is called more than
+// once, the responses are returned in sequence to successive matching calls;
+// when all responses have been returned the last response is repeated.
+//
+// An expectation with no responses returns a zero-value result and nil error.
+func (e *Expectation[TRequest, TResult]) Return(result TResult, err error) *Expectation[TRequest, TResult] {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+
+	e.responses = append(e.responses, response[TResult]{result: result, err: err})
+	return e
+}
+
+// Times establishes that the expectation must be satisfied exactly the specified
+// number of times.  Any further matching calls are not satisfied by the
+// expectation.
+//
+// If Times is not specified, the expectation must be satisfied at least once,
+// and satisfies any number of matching calls.
+func (e *Expectation[TRequest, TResult]) Times(n int) *Expectation[TRequest, TResult] {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+
+	e.times = n
+	return e
+}
+
+// Once establishes that the expectation must be satisfied exactly once.
+func (e *Expectation[TRequest, TResult]) Once() *Expectation[TRequest, TResult] {
+	return e.Times(1)
+}
+
+// Never establishes that no call may satisfy the expectation; any matching
+// call is reported as an unexpected call.
+func (e *Expectation[TRequest, TResult]) Never() *Expectation[TRequest, TResult] {
+	return e.Times(0)
+}
+
+// met returns true if the expectation has been satisfied the required number
+// of times.
+func (e *Expectation[TRequest, TResult]) met() bool {
+	if e.times < 0 {
+		return e.calls > 0
+	}
+	return e.calls == e.times
+}
+
+// wanted describes the number of calls expected.
+func (e *Expectation[TRequest, TResult]) wanted() string {
+	switch {
+	case e.times == 0:
+		return "never"
+	case e.times == 1:
+		return "once"
+	case e.times > 1:
+		return fmt.Sprintf("%d times", e.times)
+	}
+	return "at least once"
+}
+
+// UnexpectedRequestError is returned by a mock command with expectations when
+// it receives a request that does not satisfy any expectation.
+type UnexpectedRequestError struct {
+	request any
+}
+
+func (e UnexpectedRequestError) Error() string {
+	return fmt.Sprintf("unexpected request: %+v", e.request)
+}
+
+// On establishes an expectation for requests satisfying the specified matcher.
+// Requests are matched against expectations in the order in which the
+// expectations were established; the first expectation which matches a
+// request (and has not already been satisfied the number of times specified
+// by Times) provides the response to that request.
+//
+//	mock := mediator.MockCommand[getFoo.Request, *getFoo.Result]()
+//	mock.On(mediator.Equal(getFoo.Request{Id: "1"})).Return(foo1, nil).Once()
+//	mock.On(mediator.Partial(getFoo.Request{Id: "2"})).Return(nil, ErrNotFound)
+//
+// Once any expectation is established on a mock, the response of the mock is
+// determined by its expectations; a request which does not satisfy any
+// expectation is recorded as an unexpected call and an UnexpectedRequestError
+// returned.
+func (mock *Mock[TRequest, TResult]) On(m Matcher[TRequest]) *Expectation[TRequest, TResult] {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	e := &Expectation[TRequest, TResult]{mock: mock, matcher: m, times: -1}
+	mock.expectations = append(mock.expectations, e)
+	return e
+}
+
+// expected returns the response to a request determined by the expectations
+// of the mock.
+func (mock *Mock[TRequest, TResult]) expected(_ context.Context, rq TRequest) (TResult, error) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	for _, e := range mock.expectations {
+		if !e.matcher.Match(rq) {
+			continue
+		}
+		if e.times == 0 {
+			break
+		}
+		if e.times > 0 && e.calls >= e.times {
+			continue
+		}
+		e.calls++
+		if len(e.responses) == 0 {
+			return *new(TResult), nil
+		}
+		i := e.calls - 1
+		if i >= len(e.responses) {
+			i = len(e.responses) - 1
+		}
+		return e.responses[i].result, e.responses[i].err
+	}
+
+	mock.unexpected = append(mock.unexpected, rq)
+	return *new(TResult), UnexpectedRequestError{request: rq}
+}
+
+// TestingT is the subset of testing.TB used by AssertExpectations to report
+// failures.
+type TestingT interface {
+	Helper()
+	Error(args ...any)
+	Errorf(format string, args ...any)
+}
+
+// AssertExpectations reports (as test errors) any expectations established
+// on the mock which have not been met and any calls which did not satisfy any
+// expectation, returning true if there were no such failures.
+//
+// For each unexpected call, the differences between the request and each
+// expectation established with an Equal or Partial matcher are reported.
+func (mock *Mock[TRequest, TResult]) AssertExpectations(t TestingT) bool {
+	t.Helper()
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	ok := true
+	for _, e := range mock.expectations {
+		if !e.met() {
+			ok = false
+			t.Errorf("unmet expectation: On(%s): wanted %s, got %d call(s)", e.matcher, e.wanted(), e.calls)
+		}
+	}
+
+	for _, rq := range mock.unexpected {
+		ok = false
+		sb := &strings.Builder{}
+		fmt.Fprintf(sb, "unexpected call: %+v", rq)
+		for _, e := range mock.expectations {
+			d, isDiffer := e.matcher.(differ[TRequest])
+			if !isDiffer {
+				continue
+			}
+			fmt.Fprintf(sb, "\n  On(%s):", e.matcher)
+			diffs := d.diff(rq)
+			if len(diffs) == 0 {
+				fmt.Fprintf(sb, "\n    matches, but wanted %s", e.wanted())
+				if e.times > 0 {
+					fmt.Fprintf(sb, " (already called %d time(s))", e.calls)
+				}
+			}
+			for _, s := range diffs {
+				fmt.Fprintf(sb, "\n    %s", s)
+			}
+		}
+		t.Error(sb.String())
+	}
+
+	return ok
+}
@@ -0,0 +1,312 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// expectationstestrequest is a request type used for testing expectations.
+type expectationstestrequest struct {
+	Id   string
+	Name string
+	Tags struct {
+		Colour string
+		Size   int
+	}
+}
+
+// faketb is a testing.TB which records errors reported by the code under
+// test rather than failing the test.
+type faketb struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (tb *faketb) Helper()           {}
+func (tb *faketb) Error(args ...any) { tb.errors = append(tb.errors, fmt.Sprint(args...)) }
+func (tb *faketb) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+func (tb *faketb) Fatalf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+func (tb *faketb) Cleanup(fn func()) { tb.cleanups = append(tb.cleanups, fn) }
+
+func TestMatchers(t *testing.T) {
+	// ARRANGE
+	rq := expectationstestrequest{Id: "1", Name: "foo"}
+	rq.Tags.Colour = "red"
+
+	partial := expectationstestrequest{Id: "1"}
+	partial.Tags.Colour = "red"
+
+	testcases := []struct {
+		name    string
+		matcher Matcher[expectationstestrequest]
+		result  bool
+	}{
+		{name: "Equal (equal)", matcher: Equal(rq), result: true},
+		{name: "Equal (different)", matcher: Equal(expectationstestrequest{Id: "1"}), result: false},
+		{name: "Partial (matching)", matcher: Partial(partial), result: true},
+		{name: "Partial (different)", matcher: Partial(expectationstestrequest{Id: "2"}), result: false},
+		{name: "Partial (zero-value)", matcher: Partial(expectationstestrequest{}), result: true},
+		{name: "Matching (true)", matcher: Matching("id is 1", func(rq expectationstestrequest) bool { return rq.Id == "1" }), result: true},
+		{name: "Matching (false)", matcher: Matching("id is 2", func(rq expectationstestrequest) bool { return rq.Id == "2" }), result: false},
+		{name: "Any", matcher: Any[expectationstestrequest](), result: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ACT
+			got := tc.matcher.Match(rq)
+
+			// ASSERT
+			wanted := tc.result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	// ARRANGE
+	wanted := expectationstestrequest{Id: "1"}
+	wanted.Tags.Size = 2
+
+	got := expectationstestrequest{Id: "2", Name: "foo"}
+	got.Tags.Size = 3
+
+	t.Run("full", func(t *testing.T) {
+		// ACT
+		result := Equal(wanted).(differ[expectationstestrequest]).diff(got)
+
+		// ASSERT
+		expected := []string{
+			`Id: wanted "1", got "2"`,
+			`Name: wanted "", got "foo"`,
+			`Tags.Size: wanted 2, got 3`,
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("\nwanted %#v\ngot    %#v", expected, result)
+		}
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		// ACT
+		result := Partial(wanted).(differ[expectationstestrequest]).diff(got)
+
+		// ASSERT
+		expected := []string{
+			`Id: wanted "1", got "2"`,
+			`Tags.Size: wanted 2, got 3`,
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("\nwanted %#v\ngot    %#v", expected, result)
+		}
+	})
+
+	t.Run("non-struct", func(t *testing.T) {
+		// ACT
+		result := Equal(1).(differ[int]).diff(2)
+
+		// ASSERT
+		expected := []string{`wanted 1, got 2`}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("\nwanted %#v\ngot    %#v", expected, result)
+		}
+	})
+}
+
+func TestExpectations(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	rq1 := expectationstestrequest{Id: "1"}
+	rq2 := expectationstestrequest{Id: "2"}
+
+	t.Run("returns response of matching expectation", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[expectationstestrequest, string]()
+		defer mock.Unregister()
+		mock.On(Equal(rq1)).Return("one", nil)
+		mock.On(Equal(rq2)).Return("two", nil)
+
+		// ACT
+		result, err := Execute(ctx, rq2, new(string))
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wanted := "two"
+		got := result
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("returns responses in sequence", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[expectationstestrequest, string]()
+		defer mock.Unregister()
+		experr := errors.New("error")
+		mock.On(Any[expectationstestrequest]()).
+			Return("first", nil).
+			Return("", experr)
+
+		// ACT
+		r1, err1 := Execute(ctx, rq1, new(string))
+		r2, err2 := Execute(ctx, rq1, new(string))
+		r3, err3 := Execute(ctx, rq1, new(string))
+
+		// ASSERT
+		if r1 != "first" || err1 != nil {
+			t.Errorf("call 1: wanted %q, nil; got %q, %v", "first", r1, err1)
+		}
+		if r2 != "" || err2 != experr {
+			t.Errorf("call 2: wanted %q, %v; got %q, %v", "", experr, r2, err2)
+		}
+		if r3 != "" || err3 != experr {
+			t.Errorf("call 3: wanted %q, %v; got %q, %v", "", experr, r3, err3)
+		}
+	})
+
+	t.Run("Times limits the calls satisfied", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[expectationstestrequest, string]()
+		defer mock.Unregister()
+		mock.On(Equal(rq1)).Return("limited", nil).Once()
+		mock.On(Any[expectationstestrequest]()).Return("fallback", nil)
+
+		// ACT
+		r1, _ := Execute(ctx, rq1, new(string))
+		r2, _ := Execute(ctx, rq1, new(string))
+
+		// ASSERT
+		wanted := []string{"limited", "fallback"}
+		got := []string{r1, r2}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("unexpected request returns error", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[expectationstestrequest, string]()
+		defer mock.Unregister()
+		mock.On(Equal(rq1))
+
+		// ACT
+		_, err := Execute(ctx, rq2, new(string))
+
+		// ASSERT
+		wanted := UnexpectedRequestError{request: rq2}
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Never", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[expectationstestrequest, string]()
+		defer mock.Unregister()
+		mock.On(Equal(rq1)).Never()
+		mock.On(Any[expectationstestrequest]())
+
+		// ACT
+		_, err := Execute(ctx, rq1, new(string))
+
+		// ASSERT
+		if !errors.As(err, new(UnexpectedRequestError)) {
+			t.Errorf("wanted UnexpectedRequestError, got %v", err)
+		}
+	})
+}
+
+func TestAssertExpectations(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	rq1 := expectationstestrequest{Id: "1"}
+	rq2 := expectationstestrequest{Id: "2"}
+
+	t.Run("when all expectations are met", func(t *testing.T) {
+		// ARRANGE
+		tb := &faketb{}
+		mock := MockCommand[expectationstestrequest, string]()
+		defer mock.Unregister()
+		mock.On(Equal(rq1)).Once()
+		mock.On(Equal(rq2)).Times(2)
+		_, _ = Execute(ctx, rq1, new(string))
+		_, _ = Execute(ctx, rq2, new(string))
+		_, _ = Execute(ctx, rq2, new(string))
+
+		// ACT
+		ok := mock.AssertExpectations(tb)
+
+		// ASSERT
+		if !ok || len(tb.errors) > 0 {
+			t.Errorf("unexpected failures: %v", tb.errors)
+		}
+	})
+
+	t.Run("reports unmet expectations", func(t *testing.T) {
+		// ARRANGE
+		tb := &faketb{}
+		mock := MockCommand[expectationstestrequest, string]()
+		defer mock.Unregister()
+		mock.On(Equal(rq1))
+		mock.On(Equal(rq2)).Times(2)
+		_, _ = Execute(ctx, rq2, new(string))
+
+		// ACT
+		ok := mock.AssertExpectations(tb)
+
+		// ASSERT
+		wanted := []string{
+			"unmet expectation: On(Equal({Id:1 Name: Tags:{Colour: Size:0}})): wanted at least once, got 0 call(s)",
+			"unmet expectation: On(Equal({Id:2 Name: Tags:{Colour: Size:0}})): wanted 2 times, got 1 call(s)",
+		}
+		got := tb.errors
+		if ok || !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("reports unexpected calls", func(t *testing.T) {
+		// ARRANGE
+		tb := &faketb{}
+		mock := MockCommand[expectationstestrequest, string]()
+		defer mock.Unregister()
+		mock.On(Partial(rq1)).Once()
+		mock.On(Matching("never", func(expectationstestrequest) bool { return false })).Never()
+		_, _ = Execute(ctx, rq1, new(string))
+		_, _ = Execute(ctx, rq1, new(string))
+		_, _ = Execute(ctx, rq2, new(string))
+
+		// ACT
+		ok := mock.AssertExpectations(tb)
+
+		// ASSERT
+		wanted := []string{
+			strings.Join([]string{
+				"unexpected call: {Id:1 Name: Tags:{Colour: Size:0}}",
+				"  On(Partial({Id:1 Name: Tags:{Colour: Size:0}})):",
+				"    matches, but wanted once (already called 1 time(s))",
+			}, "\n"),
+			strings.Join([]string{
+				"unexpected call: {Id:2 Name: Tags:{Colour: Size:0}}",
+				"  On(Partial({Id:1 Name: Tags:{Colour: Size:0}})):",
+				`    Id: wanted "1", got "2"`,
+			}, "\n"),
+		}
+		got := tb.errors
+		if ok || !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
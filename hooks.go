@@ -0,0 +1,139 @@
+package mediator
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ExecutionEvent describes the execution of a request, passed to execution
+// hooks.
+type ExecutionEvent struct {
+	RequestType reflect.Type  // the type of the request
+	Request     any           // the request
+	Handler     any           // the command registered for the request type (nil if none)
+	Result      any           // the result of the command (AfterExecute only)
+	Err         error         // any error (AfterValidate, AfterExecute, OnNoCommand and OnRejected only)
+	Duration    time.Duration // the time taken to execute the request (AfterExecute only)
+}
+
+// RegistrationEvent describes the registration of a command, passed to the
+// OnRegister hook.
+type RegistrationEvent struct {
+	RequestType reflect.Type // the type of request handled by the command
+	Tenant      string       // the tenant for which the command is registered (if any)
+	Name        string       // the name with which the command is registered (if any)
+	Handler     any          // the command
+	Err         error        // any error returned by the registration function
+}
+
+// Hooks is a set of callbacks called by the mediator to observe the
+// registration of commands and execution of requests, e.g. for logging,
+// metrics or debugging.  Any hook may be nil.
+//
+// Hooks are called synchronously, so should not block, and must be safe for
+// concurrent use.  Hooks observe, but cannot change, the outcome of a request.
+type Hooks struct {
+	// BeforeExecute is called when a command returning the expected result
+	// type has been identified for a request, before the request is authorized,
+	// validated and executed.
+	BeforeExecute func(context.Context, ExecutionEvent)
+
+	// AfterValidate is called after a request has been validated by a command
+	// implementing Validator, with any ValidationError.
+	AfterValidate func(context.Context, ExecutionEvent)
+
+	// AfterExecute is called after every request for which BeforeExecute was
+	// called, with the result and any error (including errors from
+	// authorization and validation).  If the command panics, AfterExecute is
+	// called with a *PanicError before the panic is resumed.
+	AfterExecute func(context.Context, ExecutionEvent)
+
+	// OnNoCommand is called when no command is registered for a request (or
+	// no command is registered with the name specified or selected, or the
+	// command registered for an interface is ambiguous), with the error
+	// identifying which (NoCommandForRequestTypeError, NoCommandForKeyError or
+	// AmbiguousCommandError).
+	OnNoCommand func(context.Context, ExecutionEvent)
+
+	// OnResultTypeMismatch is called when the command registered for a
+	// request does not return the result type expected by the caller.
+	OnResultTypeMismatch func(context.Context, ExecutionEvent)
+
+	// OnRejected is called when a request is rejected before a command is
+	// identified for it, because the request type is already being executed
+	// in the chain of nested requests (CommandCycleError) or the maximum depth
+	// of nested requests would be exceeded (ExecutionDepthError), with the
+	// error.
+	OnRejected func(context.Context, ExecutionEvent)
+
+	// OnRegister is called when a command is registered using RegisterCommand,
+	// RegisterTenantCommand, RegisterNamedCommand or RegisterInterfaceCommand,
+	// whether or not the registration succeeds.  For a command registered for
+	// an interface the RequestType of the event is the interface type.
+	OnRegister func(context.Context, RegistrationEvent)
+}
+
+// hooks holds the hooks established by AddHooks.
+var hooks = struct {
+	sync.RWMutex
+	sets []*Hooks
+}{}
+
+// AddHooks establishes a set of hooks, returning a function which removes
+// them.  Any number of sets of hooks may be established; each hook is called
+// in the order in which the sets were added.
+func AddHooks(h Hooks) func() {
+	hp := &h
+
+	hooks.Lock()
+	defer hooks.Unlock()
+
+	hooks.sets = append(hooks.sets, hp)
+
+	return func() {
+		hooks.Lock()
+		defer hooks.Unlock()
+
+		sets := make([]*Hooks, 0, len(hooks.sets))
+		for _, each := range hooks.sets {
+			if each != hp {
+				sets = append(sets, each)
+			}
+		}
+		hooks.sets = sets
+	}
+}
+
+// fireHook calls the hook selected from each established set of hooks, if
+// not nil, with the specified event.
+func fireHook[T any](ctx context.Context, hook func(*Hooks) func(context.Context, T), event T) {
+	hooks.RLock()
+	sets := hooks.sets
+	hooks.RUnlock()
+
+	for _, h := range sets {
+		if fn := hook(h); fn != nil {
+			fn(ctx, event)
+		}
+	}
+}
+
+// hasHooks returns true if any hooks have been established.
+func hasHooks() bool {
+	hooks.RLock()
+	defer hooks.RUnlock()
+
+	return len(hooks.sets) > 0
+}
+
+func beforeExecute(h *Hooks) func(context.Context, ExecutionEvent) { return h.BeforeExecute }
+func afterValidate(h *Hooks) func(context.Context, ExecutionEvent) { return h.AfterValidate }
+func afterExecute(h *Hooks) func(context.Context, ExecutionEvent)  { return h.AfterExecute }
+func onNoCommand(h *Hooks) func(context.Context, ExecutionEvent)   { return h.OnNoCommand }
+func onResultTypeMismatch(h *Hooks) func(context.Context, ExecutionEvent) {
+	return h.OnResultTypeMismatch
+}
+func onRejected(h *Hooks) func(context.Context, ExecutionEvent)    { return h.OnRejected }
+func onRegister(h *Hooks) func(context.Context, RegistrationEvent) { return h.OnRegister }
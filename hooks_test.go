@@ -0,0 +1,306 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// hookstestrequest is a request type used for testing hooks.
+type hookstestrequest struct{ invalid bool }
+
+// hookstestrecorder records the hooks called.
+type hookstestrecorder struct {
+	calls  []string
+	events []ExecutionEvent
+}
+
+func (rec *hookstestrecorder) hooks() Hooks {
+	record := func(name string) func(context.Context, ExecutionEvent) {
+		return func(_ context.Context, event ExecutionEvent) {
+			rec.calls = append(rec.calls, name)
+			rec.events = append(rec.events, event)
+		}
+	}
+	return Hooks{
+		BeforeExecute:        record("BeforeExecute"),
+		AfterValidate:        record("AfterValidate"),
+		AfterExecute:         record("AfterExecute"),
+		OnNoCommand:          record("OnNoCommand"),
+		OnResultTypeMismatch: record("OnResultTypeMismatch"),
+		OnRejected:           record("OnRejected"),
+		OnRegister: func(_ context.Context, event RegistrationEvent) {
+			rec.calls = append(rec.calls, "OnRegister")
+		},
+	}
+}
+
+func TestExecutionHooks(t *testing.T) {
+	// ARRANGE
+	rqt := reflect.TypeOf(hookstestrequest{})
+	verr := errors.New("invalid")
+
+	testcases := []struct {
+		scenario string
+		register bool
+		exec     func(context.Context) error
+		calls    []string
+	}{
+		{scenario: "no command",
+			exec: func(ctx context.Context) error {
+				_, err := Execute(ctx, hookstestrequest{}, new(int))
+				return err
+			},
+			calls: []string{"OnNoCommand"},
+		},
+		{scenario: "result type mismatch",
+			register: true,
+			exec: func(ctx context.Context) error {
+				_, err := Execute(ctx, hookstestrequest{}, new(string))
+				return err
+			},
+			calls: []string{"OnResultTypeMismatch"},
+		},
+		{scenario: "successful execution",
+			register: true,
+			exec: func(ctx context.Context) error {
+				_, err := Execute(ctx, hookstestrequest{}, new(int))
+				return err
+			},
+			calls: []string{"BeforeExecute", "AfterValidate", "AfterExecute"},
+		},
+		{scenario: "validation error",
+			register: true,
+			exec: func(ctx context.Context) error {
+				_, err := Execute(ctx, hookstestrequest{invalid: true}, new(int))
+				return err
+			},
+			calls: []string{"BeforeExecute", "AfterValidate", "AfterExecute"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			ctx := testContext(t)
+			if tc.register {
+				_ = mockCommandFuncT(t,
+					func(_ context.Context, rq hookstestrequest) error {
+						if rq.invalid {
+							return verr
+						}
+						return nil
+					},
+					func(context.Context, hookstestrequest) (int, error) { return 42, nil },
+				)
+			}
+
+			rec := &hookstestrecorder{}
+			remove := AddHooks(rec.hooks())
+			defer remove()
+
+			// ACT
+			_ = tc.exec(ctx)
+
+			// ASSERT
+			wanted := tc.calls
+			got := rec.calls
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+
+			for _, event := range rec.events {
+				wanted := rqt
+				got := event.RequestType
+				if wanted != got {
+					t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+				}
+			}
+		})
+	}
+
+	t.Run("rejected nested request", func(t *testing.T) {
+		// ARRANGE
+		ctx := testContext(t)
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, rq hookstestrequest) (int, error) {
+			return Execute(ctx, rq, new(int))
+		})
+
+		rec := &hookstestrecorder{}
+		remove := AddHooks(rec.hooks())
+		defer remove()
+
+		// ACT
+		_, _ = Execute(ctx, hookstestrequest{}, new(int))
+
+		// ASSERT
+		wanted := []string{"BeforeExecute", "AfterValidate", "OnRejected", "AfterExecute"}
+		got := rec.calls
+		if !reflect.DeepEqual(wanted, got) {
+			t.Fatalf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+
+		t.Run("OnRejected event", func(t *testing.T) {
+			event := rec.events[2]
+			wanted := []any{rqt, hookstestrequest{}, nil, true}
+			got := []any{event.RequestType, event.Request, event.Handler, errors.As(event.Err, new(*CommandCycleError))}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("OnNoCommand event", func(t *testing.T) {
+		testcases := []struct {
+			scenario string
+			exec     func(context.Context)
+			err      error
+		}{
+			{scenario: "no command registered",
+				exec: func(ctx context.Context) { _, _ = Execute(ctx, hookstestrequest{}, new(int)) },
+				err:  &NoCommandForRequestTypeError{},
+			},
+			{scenario: "no command registered with name",
+				exec: func(ctx context.Context) { _, _ = ExecuteNamed(ctx, "name", hookstestrequest{}, new(int)) },
+				err:  &NoCommandForKeyError{},
+			},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.scenario, func(t *testing.T) {
+				// ARRANGE
+				var event ExecutionEvent
+				remove := AddHooks(Hooks{OnNoCommand: func(_ context.Context, e ExecutionEvent) { event = e }})
+				defer remove()
+
+				// ACT
+				tc.exec(testContext(t))
+
+				// ASSERT
+				wanted := reflect.TypeOf(tc.err)
+				got := reflect.TypeOf(event.Err)
+				if wanted != got {
+					t.Errorf("\nwanted %v\ngot    %v", wanted, got)
+				}
+			})
+		}
+	})
+
+	t.Run("AfterExecute event", func(t *testing.T) {
+		// ARRANGE
+		ctx := testContext(t)
+		mock := mockCommandResultT[hookstestrequest, int](t, 42)
+
+		var event ExecutionEvent
+		remove := AddHooks(Hooks{AfterExecute: func(_ context.Context, e ExecutionEvent) { event = e }})
+		defer remove()
+
+		// ACT
+		_, _ = Execute(ctx, hookstestrequest{}, new(int))
+
+		// ASSERT
+		wanted := []any{hookstestrequest{}, any(mock), 42, nil}
+		got := []any{event.Request, event.Handler, event.Result, event.Err}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("AfterExecute event when the command panics", func(t *testing.T) {
+		// ARRANGE
+		ctx := testContext(t)
+		_ = mockCommandFuncT(t, nil, func(context.Context, hookstestrequest) (int, error) {
+			panic("command panicked")
+		})
+
+		var event ExecutionEvent
+		remove := AddHooks(Hooks{AfterExecute: func(_ context.Context, e ExecutionEvent) { event = e }})
+		defer remove()
+
+		// ACT
+		func() {
+			defer func() { _ = recover() }()
+			_, _ = Execute(ctx, hookstestrequest{}, new(int))
+		}()
+
+		// ASSERT
+		perr := &PanicError{}
+		if !errors.As(event.Err, &perr) {
+			t.Fatalf("\nwanted *PanicError\ngot    %#v", event.Err)
+		}
+		wanted := any("command panicked")
+		got := perr.Value
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("AfterValidate event", func(t *testing.T) {
+		// ARRANGE
+		ctx := testContext(t)
+		_ = mockCommandValidationErrorT[hookstestrequest, int](t, verr)
+
+		var event ExecutionEvent
+		remove := AddHooks(Hooks{AfterValidate: func(_ context.Context, e ExecutionEvent) { event = e }})
+		defer remove()
+
+		// ACT
+		_, _ = Execute(ctx, hookstestrequest{}, new(int))
+
+		// ASSERT
+		wanted := true
+		got := errors.Is(event.Err, verr) && errors.As(event.Err, new(ValidationError))
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestRegistrationHooks(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	cfgerr := errors.New("configuration error")
+
+	var events []RegistrationEvent
+	remove := AddHooks(Hooks{OnRegister: func(_ context.Context, e RegistrationEvent) { events = append(events, e) }})
+	defer remove()
+
+	// ACT
+	_ = RegisterCommand[int, NoResultType](ctx, registrationtestcmd{cfgerr})
+	_ = RegisterCommand[int, NoResultType](ctx, registrationtestcmd{})
+	defer delete(commands, reflect.TypeOf(0))
+
+	// ASSERT
+	wanted := []RegistrationEvent{
+		{RequestType: reflect.TypeOf(0), Handler: registrationtestcmd{cfgerr}, Err: cfgerr},
+		{RequestType: reflect.TypeOf(0), Handler: registrationtestcmd{}},
+	}
+	got := events
+	if !reflect.DeepEqual(wanted, got) {
+		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+	}
+}
+
+func TestAddHooks(t *testing.T) {
+	// ARRANGE
+	ctx := testContext(t)
+	calls := []string{}
+	hook := func(name string) Hooks {
+		return Hooks{OnNoCommand: func(context.Context, ExecutionEvent) { calls = append(calls, name) }}
+	}
+
+	removeA := AddHooks(hook("a"))
+	removeB := AddHooks(hook("b"))
+	defer removeB()
+
+	// ACT
+	_, _ = Execute(ctx, hookstestrequest{}, new(int))
+	removeA()
+	_, _ = Execute(ctx, hookstestrequest{}, new(int))
+
+	// ASSERT
+	wanted := []string{"a", "b", "b"}
+	got := calls
+	if !reflect.DeepEqual(wanted, got) {
+		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+	}
+}
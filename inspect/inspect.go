@@ -0,0 +1,143 @@
+// Package inspect provides an http.Handler rendering the commands registered
+// with the mediator and statistics for the requests executed, as HTML or
+// JSON.  This enables operators to inspect a running service without adding
+// logging.
+//
+// Like net/http/pprof, the handler is typically mounted on an internal or
+// debug endpoint:
+//
+//	mux.Handle("/debug/mediator", inspect.Handler())
+//
+// JSON is rendered if the request has a query parameter "format=json" or
+// an Accept header specifying "application/json"; otherwise HTML is rendered.
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/blugnu/mediator"
+)
+
+// Report is the information rendered by the handler.
+type Report struct {
+	Time          time.Time                   `json:"time"`
+	Registrations []mediator.RegistrationInfo `json:"registrations"`
+	Stats         []mediator.CommandStats     `json:"stats"`
+}
+
+// Handler returns an http.Handler rendering the current registrations and
+// execution statistics of the mediator.
+func Handler() http.Handler {
+	return http.HandlerFunc(serve)
+}
+
+// serve renders a Report as HTML or JSON.
+func serve(w http.ResponseWriter, r *http.Request) {
+	report := Report{
+		Time:          time.Now(),
+		Registrations: mediator.Registrations(),
+		Stats:         mediator.Stats(),
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := page.Execute(w, report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// wantsJSON returns true if the request asks for JSON.
+func wantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// duration formats a duration for display, rounded to a precision appropriate
+// to its magnitude.
+func duration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "-"
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < time.Second:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// percent formats a rate as a percentage.
+func percent(rate float64) string {
+	return fmt.Sprintf("%.1f%%", rate*100)
+}
+
+// yesNo formats a bool as "yes" or "no".
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// page is the template for the HTML rendering of a Report.
+var page = template.Must(template.New("inspect").Funcs(template.FuncMap{
+	"duration": duration,
+	"percent":  percent,
+	"yesNo":    yesNo,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>mediator</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>mediator</h1>
+<p>{{ .Time.Format "2006-01-02 15:04:05 MST" }} (<a href="?format=json">json</a>)</p>
+
+<h2>Registrations</h2>
+{{ if .Registrations -}}
+<table>
+<tr><th>Request</th><th>Name</th><th>Tenant</th><th>Priority</th><th>Result</th><th>Handler</th><th>Validates</th><th>Checks Configuration</th></tr>
+{{- range .Registrations }}
+<tr><td>{{ .RequestType }}</td><td>{{ .Name }}</td><td>{{ if .Tenant }}{{ .Tenant }}{{ else }}<i>all</i>{{ end }}</td><td>{{ if .Interface }}{{ .Priority }}{{ end }}</td><td>{{ .ResultType }}</td><td>{{ .HandlerType }}</td><td>{{ yesNo .Validates }}</td><td>{{ yesNo .ChecksConfiguration }}</td></tr>
+{{- end }}
+</table>
+{{- else -}}
+<p>No commands are registered.</p>
+{{- end }}
+
+<h2>Executions</h2>
+{{ if .Stats -}}
+<table>
+<tr><th>Request</th><th>Calls</th><th>Errors</th><th>Error Rate</th><th>p50</th><th>p90</th><th>p99</th><th>Max</th><th>In Flight</th></tr>
+{{- range .Stats }}
+<tr><td>{{ .RequestType }}</td><td class="num">{{ .Calls }}</td><td class="num">{{ .Errors }}</td><td class="num">{{ percent .ErrorRate }}</td><td class="num">{{ duration .Latency.P50 }}</td><td class="num">{{ duration .Latency.P90 }}</td><td class="num">{{ duration .Latency.P99 }}</td><td class="num">{{ duration .Latency.Max }}</td><td>{{ range $i, $e := .InFlight }}{{ if $i }}, {{ end }}{{ duration $e.Elapsed }}{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- else -}}
+<p>No requests have been executed.</p>
+{{- end }}
+</body>
+</html>
+`))
@@ -0,0 +1,152 @@
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/mediator"
+)
+
+// request is a request type used for testing the handler.
+type request struct{}
+
+func TestHandler(t *testing.T) {
+	// ARRANGE
+	mock := mediator.MockCommandResult[request, int](42)
+	defer mock.Unregister()
+
+	if _, err := mediator.Execute(context.Background(), request{}, new(int)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const rqt = "github.com/blugnu/mediator/inspect.request"
+
+	serve := func(t *testing.T, target string, accept string) *httptest.ResponseRecorder {
+		t.Helper()
+		rq := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			rq.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		Handler().ServeHTTP(rec, rq)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("\nwanted status %d\ngot    %d", http.StatusOK, rec.Code)
+		}
+		return rec
+	}
+
+	t.Run("renders json", func(t *testing.T) {
+		testcases := []struct {
+			scenario string
+			target   string
+			accept   string
+		}{
+			{scenario: "format query parameter", target: "/debug/mediator?format=json"},
+			{scenario: "accept header", target: "/debug/mediator", accept: "application/json"},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.scenario, func(t *testing.T) {
+				// ACT
+				rec := serve(t, tc.target, tc.accept)
+
+				// ASSERT
+				t.Run("content type", func(t *testing.T) {
+					wanted := "application/json"
+					got := rec.Header().Get("Content-Type")
+					if wanted != got {
+						t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+					}
+				})
+
+				report := Report{}
+				if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				t.Run("registrations", func(t *testing.T) {
+					wanted := mediator.RegistrationInfo{
+						RequestType: rqt,
+						ResultType:  "int",
+						HandlerType: "*github.com/blugnu/mediator.Mock[github.com/blugnu/mediator/inspect.request,int]",
+						Validates:   true,
+					}
+					got := mediator.RegistrationInfo{}
+					for _, reg := range report.Registrations {
+						if reg.RequestType == rqt {
+							got = reg
+						}
+					}
+					if wanted != got {
+						t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+					}
+				})
+
+				t.Run("stats", func(t *testing.T) {
+					wanted := []any{uint64(1), uint64(0), 1}
+					got := []any{}
+					for _, s := range report.Stats {
+						if s.RequestType == rqt {
+							got = []any{s.Calls, s.Errors, s.Latency.Samples}
+						}
+					}
+					if !reflect.DeepEqual(wanted, got) {
+						t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+					}
+				})
+			})
+		}
+	})
+
+	t.Run("renders html", func(t *testing.T) {
+		// ACT
+		rec := serve(t, "/debug/mediator", "text/html")
+
+		// ASSERT
+		t.Run("content type", func(t *testing.T) {
+			wanted := "text/html; charset=utf-8"
+			got := rec.Header().Get("Content-Type")
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("request type", func(t *testing.T) {
+			wanted := 2 // a registration and an execution
+			got := strings.Count(rec.Body.String(), "<td>"+rqt+"</td>")
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+}
+
+func TestDuration(t *testing.T) {
+	testcases := []struct {
+		d      time.Duration
+		result string
+	}{
+		{d: 0, result: "-"},
+		{d: 1234 * time.Nanosecond, result: "1µs"},
+		{d: 12345678 * time.Nanosecond, result: "12.35ms"},
+		{d: 1234567890 * time.Nanosecond, result: "1.235s"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.result, func(t *testing.T) {
+			// ACT
+			result := duration(tc.d)
+
+			// ASSERT
+			wanted := tc.result
+			got := result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package mediator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// interfaceRegistration is a command registered for requests implementing an
+// interface.
+type interfaceRegistration struct {
+	iface    reflect.Type
+	priority int
+	cmd      any
+}
+
+// interfaceCommands holds the commands registered for interfaces, sorted by
+// descending priority.
+var interfaceCommands = struct {
+	sync.RWMutex
+	regs []interfaceRegistration
+}{}
+
+// RegisterInterfaceCommand[TInterface, TResult] registers a command returning
+// a specific result type for requests of any type implementing the specified
+// interface type (e.g. a command which handles all AuditableRequests):
+//
+//	_ = mediator.RegisterInterfaceCommand[AuditableRequest, mediator.NoResultType](ctx, 0, &AuditCommand{})
+//
+// A command registered for a specific request type (using RegisterCommand) is
+// always preferred.  If there is no command registered for a request type,
+// the request is executed by the command registered for an interface
+// implemented by the request type with the highest priority.
+//
+// If a command is already registered for the interface the function will
+// return a CommandAlreadyRegisteredError (identifying the request type as a
+// pointer to the interface).  If a command is registered with the
+// same priority for an interface which is implemented by, or implements, the
+// interface, the registration would be ambiguous and an AmbiguousCommandError
+// is returned.  If TInterface is not an interface type an error is returned.
+//
+// If the command being registered implements the ConfigurationChecker
+// interface, this is called and any error returned.
+//
+// Any OnRegister hooks established using AddHooks are called with the outcome.
+func RegisterInterfaceCommand[TInterface any, TResult any](ctx context.Context, priority int, cmd CommandHandler[TInterface, TResult]) error {
+	iface := reflect.TypeOf((*TInterface)(nil)).Elem()
+	_, err := registerInterface(ctx, iface, priority, cmd)
+	fireHook(ctx, onRegister, RegistrationEvent{RequestType: iface, Handler: cmd, Err: err})
+	return err
+}
+
+// registerInterface registers a command for an interface, returning a function
+// which removes the registration.
+func registerInterface(ctx context.Context, iface reflect.Type, priority int, cmd any) (func(), error) {
+	if iface.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("%v is not an interface type", iface)
+	}
+
+	// conflict returns an error if the registration conflicts with an existing
+	// registration; the caller must hold (at least) a read lock
+	conflict := func() error {
+		for _, reg := range interfaceCommands.regs {
+			switch {
+			case reg.iface == iface:
+				return CommandAlreadyRegisteredError{command: reg.cmd, requestType: iface}
+			case reg.priority == priority && (reg.iface.Implements(iface) || iface.Implements(reg.iface)):
+				return &AmbiguousCommandError{requestType: iface, interfaces: []reflect.Type{reg.iface, iface}}
+			}
+		}
+		return nil
+	}
+
+	interfaceCommands.RLock()
+	err := conflict()
+	interfaceCommands.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// call the ConfigurationChecker, if implemented; this is called without
+	// holding the lock, since it may execute requests resolved using interface
+	// registrations
+	if cfg, ok := cmd.(ConfigurationChecker); ok {
+		if err := cfg.CheckConfiguration(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	interfaceCommands.Lock()
+	defer interfaceCommands.Unlock()
+
+	if err := conflict(); err != nil {
+		return nil, err
+	}
+	interfaceCommands.regs = append(interfaceCommands.regs, interfaceRegistration{iface: iface, priority: priority, cmd: cmd})
+	sort.SliceStable(interfaceCommands.regs, func(i, j int) bool {
+		return interfaceCommands.regs[i].priority > interfaceCommands.regs[j].priority
+	})
+
+	return func() {
+		interfaceCommands.Lock()
+		defer interfaceCommands.Unlock()
+
+		regs := make([]interfaceRegistration, 0, len(interfaceCommands.regs))
+		for _, reg := range interfaceCommands.regs {
+			if reg.iface != iface {
+				regs = append(regs, reg)
+			}
+		}
+		interfaceCommands.regs = regs
+	}, nil
+}
+
+// lookupInterface returns the registration of the command for the interface
+// with the highest priority implemented by the specified request type.  If
+// more than one interface with the highest priority is implemented an
+// AmbiguousCommandError is returned.
+func lookupInterface(rqt reflect.Type) (interfaceRegistration, bool, error) {
+	interfaceCommands.RLock()
+	defer interfaceCommands.RUnlock()
+
+	var matches []interfaceRegistration
+	for _, reg := range interfaceCommands.regs {
+		if len(matches) > 0 && reg.priority < matches[0].priority {
+			break
+		}
+		if rqt != nil && rqt.Implements(reg.iface) {
+			matches = append(matches, reg)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return interfaceRegistration{}, false, nil
+	case 1:
+		return matches[0], true, nil
+	}
+
+	err := &AmbiguousCommandError{requestType: rqt}
+	for _, reg := range matches {
+		err.interfaces = append(err.interfaces, reg.iface)
+	}
+	return interfaceRegistration{}, false, err
+}
+
+// ResolveCommand returns a description of the command which executes requests
+// of the specified type, e.g. to identify which command registered for an
+// interface a request type resolves to.  Commands registered in a test
+// registry, for a tenant or with a name are not considered.  With pointer
+// equivalence (see SetPointerEquivalence) the command for a pointer type may
+// be that registered for the type to which it points.
+//
+// If no command would execute requests of the type a NoCommandForRequestTypeError
+// is returned; if the resolution is ambiguous an AmbiguousCommandError is
+// returned.
+func ResolveCommand(rqt reflect.Type) (RegistrationInfo, error) {
+	if cmd, ok := commands[rqt]; ok {
+		return registrationInfo(rqt, cmd), nil
+	}
+	if cmd, ok := lookupEquivalent(context.Background(), rqt); ok {
+		return registrationInfo(rqt.Elem(), cmd), nil
+	}
+
+	reg, ok, err := lookupInterface(rqt)
+	switch {
+	case err != nil:
+		return RegistrationInfo{}, err
+	case !ok:
+		var rq any
+		if rqt != nil {
+			rq = reflect.Zero(rqt).Interface()
+		}
+		return RegistrationInfo{}, &NoCommandForRequestTypeError{request: rq, nearMisses: nearMisses(context.Background(), rqt)}
+	}
+
+	return interfaceRegistrationInfo(reg), nil
+}
+
+// interfaceRegistrationInfo returns a description of a command registered for
+// an interface.
+func interfaceRegistrationInfo(reg interfaceRegistration) RegistrationInfo {
+	info := registrationInfo(reg.iface, reg.cmd)
+	info.Interface = true
+	info.Priority = reg.priority
+	return info
+}
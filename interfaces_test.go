@@ -0,0 +1,410 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// auditable and traceable are interfaces used for testing interface
+// registrations; auditabletraceable embeds both.
+type auditable interface{ AuditKey() string }
+type traceable interface{ TraceKey() string }
+type auditabletraceable interface {
+	auditable
+	traceable
+}
+
+// interfacetestrequest implements auditable; interfacetestrequest2 implements
+// both auditable and traceable.
+type interfacetestrequest struct{ key string }
+type interfacetestrequest2 struct{ key string }
+
+func (rq interfacetestrequest) AuditKey() string  { return rq.key }
+func (rq interfacetestrequest2) AuditKey() string { return rq.key }
+func (rq interfacetestrequest2) TraceKey() string { return rq.key }
+
+// auditcmd is a command for auditable requests, returning the audit key
+// prefixed by the name of the command.
+type auditcmd string
+
+func (cmd auditcmd) Execute(_ context.Context, rq auditable) (string, error) {
+	return string(cmd) + ":" + rq.AuditKey(), nil
+}
+
+// tracecmd is a command for traceable requests.
+type tracecmd string
+
+func (cmd tracecmd) Execute(_ context.Context, rq traceable) (string, error) {
+	return string(cmd) + ":" + rq.TraceKey(), nil
+}
+
+// auditabletracecmd is a command for auditabletraceable requests.
+type auditabletracecmd string
+
+func (cmd auditabletracecmd) Execute(_ context.Context, rq auditabletraceable) (string, error) {
+	return string(cmd) + ":" + rq.AuditKey() + ":" + rq.TraceKey(), nil
+}
+
+// interfacetestcmd is a command for interfacetestrequest requests.
+type interfacetestcmd string
+
+func (cmd interfacetestcmd) Execute(context.Context, interfacetestrequest) (string, error) {
+	return string(cmd), nil
+}
+
+// guardedauditcmd is a command for auditable requests which validates and
+// authorizes requests.
+type guardedauditcmd struct{}
+
+func (guardedauditcmd) Execute(_ context.Context, rq auditable) (string, error) {
+	return rq.AuditKey(), nil
+}
+
+func (guardedauditcmd) Validate(_ context.Context, rq auditable) error {
+	if rq.AuditKey() == "" {
+		return errors.New("key is required")
+	}
+	return nil
+}
+
+func (guardedauditcmd) Authorize(_ context.Context, principal any, rq auditable) error {
+	if principal != rq.AuditKey() {
+		return errors.New("not permitted")
+	}
+	return nil
+}
+
+// resetInterfaceCommands replaces the commands registered for interfaces for
+// the duration of a test.
+func resetInterfaceCommands(t *testing.T) {
+	og := interfaceCommands.regs
+	interfaceCommands.regs = nil
+	t.Cleanup(func() { interfaceCommands.regs = og })
+}
+
+func TestRegisterInterfaceCommand(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	t.Run("when not an interface", func(t *testing.T) {
+		// ARRANGE
+		resetInterfaceCommands(t)
+
+		// ACT
+		err := RegisterInterfaceCommand[interfacetestrequest, string](ctx, 0, interfacetestcmd("exact"))
+
+		// ASSERT
+		wanted := "mediator.interfacetestrequest is not an interface type"
+		got := fmt.Sprint(err)
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when already registered", func(t *testing.T) {
+		// ARRANGE
+		resetInterfaceCommands(t)
+		_ = RegisterInterfaceCommand[auditable, string](ctx, 0, auditcmd("a"))
+
+		// ACT
+		err := RegisterInterfaceCommand[auditable, string](ctx, 1, auditcmd("b"))
+
+		// ASSERT
+		wanted := CommandAlreadyRegisteredError{requestType: reflect.TypeOf((*auditable)(nil)).Elem()}
+		got := err
+		if !errors.Is(got, wanted) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+
+		t.Run("identifies the interface", func(t *testing.T) {
+			wanted := "github.com/blugnu/mediator.auditcmd already registered for requests of type: github.com/blugnu/mediator.auditable"
+			got := err.Error()
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("ambiguity", func(t *testing.T) {
+		testcases := []struct {
+			name     string
+			priority int
+			err      error
+		}{
+			{name: "with equal priority", priority: 0, err: AmbiguousCommandError{}},
+			{name: "with different priority", priority: 1},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				// ARRANGE
+				resetInterfaceCommands(t)
+				_ = RegisterInterfaceCommand[auditable, string](ctx, 0, auditcmd("a"))
+
+				// ACT
+				err := RegisterInterfaceCommand[auditabletraceable, string](ctx, tc.priority, auditabletracecmd("at"))
+
+				// ASSERT
+				wanted := tc.err
+				got := err
+				if (wanted == nil && got != nil) || !errors.Is(got, wanted) {
+					t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+				}
+			})
+		}
+	})
+
+	t.Run("returns any ConfigurationChecker error", func(t *testing.T) {
+		// ARRANGE
+		resetInterfaceCommands(t)
+		cfgerr := errors.New("configuration error")
+
+		// ACT
+		_, err := registerInterface(ctx, reflect.TypeOf((*auditable)(nil)).Elem(), 0, registrationtestcmd{cfgerr})
+
+		// ASSERT
+		wanted := cfgerr
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when the ConfigurationChecker executes a request", func(t *testing.T) {
+		// ARRANGE
+		resetInterfaceCommands(t)
+		cmd := registrationexecutingtestcmd{exec: func(ctx context.Context) { _, _ = Execute(ctx, "request", new(int)) }}
+
+		// ACT
+		ok := completes(func() { _, _ = registerInterface(ctx, reflect.TypeOf((*auditable)(nil)).Elem(), 0, cmd) })
+
+		// ASSERT
+		if !ok {
+			t.Error("registration did not complete")
+		}
+	})
+
+	t.Run("calls OnRegister hooks", func(t *testing.T) {
+		// ARRANGE
+		resetInterfaceCommands(t)
+		var event RegistrationEvent
+		remove := AddHooks(Hooks{OnRegister: func(_ context.Context, e RegistrationEvent) { event = e }})
+		defer remove()
+
+		// ACT
+		_ = RegisterInterfaceCommand[auditable, string](ctx, 0, auditcmd("a"))
+
+		// ASSERT
+		wanted := RegistrationEvent{RequestType: reflect.TypeOf((*auditable)(nil)).Elem(), Handler: auditcmd("a")}
+		got := event
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestExecuteWithInterfaceCommands(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	t.Run("resolution", func(t *testing.T) {
+		testcases := []struct {
+			name    string
+			arrange func(t *testing.T)
+			request any
+			result  string
+			err     error
+		}{
+			{name: "no registrations",
+				request: interfacetestrequest{"key"},
+				err:     NoCommandForRequestTypeError{request: interfacetestrequest{}},
+			},
+			{name: "interface",
+				arrange: func(t *testing.T) {
+					_ = RegisterInterfaceCommand[auditable, string](ctx, 0, auditcmd("audit"))
+				},
+				request: interfacetestrequest{"key"},
+				result:  "audit:key",
+			},
+			{name: "exact type preferred",
+				arrange: func(t *testing.T) {
+					_ = RegisterInterfaceCommand[auditable, string](ctx, 0, auditcmd("audit"))
+					_ = RegisterCommand[interfacetestrequest, string](ctx, interfacetestcmd("exact"))
+					t.Cleanup(func() { delete(commands, reflect.TypeOf(interfacetestrequest{})) })
+				},
+				request: interfacetestrequest{"key"},
+				result:  "exact",
+			},
+			{name: "highest priority",
+				arrange: func(t *testing.T) {
+					_ = RegisterInterfaceCommand[auditable, string](ctx, 0, auditcmd("audit"))
+					_ = RegisterInterfaceCommand[traceable, string](ctx, 1, tracecmd("trace"))
+				},
+				request: interfacetestrequest2{"key"},
+				result:  "trace:key",
+			},
+			{name: "ambiguous",
+				arrange: func(t *testing.T) {
+					_ = RegisterInterfaceCommand[auditable, string](ctx, 0, auditcmd("audit"))
+					_ = RegisterInterfaceCommand[traceable, string](ctx, 0, tracecmd("trace"))
+				},
+				request: interfacetestrequest2{"key"},
+				err:     AmbiguousCommandError{},
+			},
+			{name: "not implemented by request",
+				arrange: func(t *testing.T) {
+					_ = RegisterInterfaceCommand[traceable, string](ctx, 0, tracecmd("trace"))
+				},
+				request: interfacetestrequest{"key"},
+				err:     NoCommandForRequestTypeError{request: interfacetestrequest{}},
+			},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				// ARRANGE
+				resetInterfaceCommands(t)
+				if tc.arrange != nil {
+					tc.arrange(t)
+				}
+
+				// ACT
+				var result string
+				var err error
+				switch rq := tc.request.(type) {
+				case interfacetestrequest:
+					result, err = Execute(ctx, rq, new(string))
+				case interfacetestrequest2:
+					result, err = Execute(ctx, rq, new(string))
+				}
+
+				// ASSERT
+				wanted := []any{tc.result, true}
+				got := []any{result, (tc.err == nil && err == nil) || errors.Is(err, tc.err)}
+				if !reflect.DeepEqual(wanted, got) {
+					t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+					t.Logf("error: %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("result type mismatch", func(t *testing.T) {
+		// ARRANGE
+		resetInterfaceCommands(t)
+		_ = RegisterInterfaceCommand[auditable, string](ctx, 0, auditcmd("audit"))
+
+		// ACT
+		_, err := Execute(ctx, interfacetestrequest{"key"}, new(int))
+
+		// ASSERT
+		wanted := ResultTypeError{command: auditcmd("audit"), result: 0}
+		got := err
+		if !errors.Is(got, wanted) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("validation and authorization", func(t *testing.T) {
+		// ARRANGE
+		resetInterfaceCommands(t)
+		_ = RegisterInterfaceCommand[auditable, string](ctx, 0, guardedauditcmd{})
+
+		testcases := []struct {
+			name      string
+			principal any
+			key       string
+			target    any
+		}{
+			{name: "invalid", principal: "", key: "", target: new(ValidationError)},
+			{name: "forbidden", principal: "other", key: "key", target: new(ForbiddenError)},
+			{name: "unauthorized", key: "key", target: new(UnauthorizedError)},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				// ARRANGE
+				ctx := ctx
+				if tc.principal != nil {
+					ctx = ContextWithPrincipal(ctx, tc.principal)
+				}
+
+				// ACT
+				_, err := Execute(ctx, interfacetestrequest{tc.key}, new(string))
+
+				// ASSERT
+				if !errors.As(err, tc.target) {
+					t.Errorf("\nwanted %T\ngot    %#v", tc.target, err)
+				}
+			})
+		}
+
+		t.Run("valid and authorized", func(t *testing.T) {
+			// ARRANGE
+			ctx := ContextWithPrincipal(ctx, "key")
+
+			// ACT
+			result, err := Execute(ctx, interfacetestrequest{"key"}, new(string))
+
+			// ASSERT
+			wanted := []any{"key", nil}
+			got := []any{result, err}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+}
+
+func TestResolveCommand(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	resetInterfaceCommands(t)
+	_ = RegisterInterfaceCommand[auditable, string](ctx, 0, auditcmd("audit"))
+	_ = RegisterInterfaceCommand[traceable, string](ctx, 1, tracecmd("trace"))
+
+	audit := RegistrationInfo{
+		RequestType: "github.com/blugnu/mediator.auditable",
+		Interface:   true,
+		ResultType:  "string",
+		HandlerType: "github.com/blugnu/mediator.auditcmd",
+	}
+	trace := RegistrationInfo{
+		RequestType: "github.com/blugnu/mediator.traceable",
+		Interface:   true,
+		Priority:    1,
+		ResultType:  "string",
+		HandlerType: "github.com/blugnu/mediator.tracecmd",
+	}
+
+	testcases := []struct {
+		request any
+		result  RegistrationInfo
+		err     error
+	}{
+		{request: interfacetestrequest{}, result: audit},
+		{request: interfacetestrequest2{}, result: trace},
+		{request: 0, err: NoCommandForRequestTypeError{request: 0}},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("%T", tc.request), func(t *testing.T) {
+			// ACT
+			result, err := ResolveCommand(reflect.TypeOf(tc.request))
+
+			// ASSERT
+			wanted := []any{tc.result, true}
+			got := []any{result, (tc.err == nil && err == nil) || errors.Is(err, tc.err)}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+
+	t.Run("registrations", func(t *testing.T) {
+		wanted := []RegistrationInfo{audit, trace}
+		got := Registrations()
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
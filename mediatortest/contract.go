@@ -0,0 +1,353 @@
+package mediatortest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blugnu/mediator"
+)
+
+// CommandContract[TRequest, TResult] is a conformance suite verifying that a
+// command behaves as the mediator relies on.  For most commands the
+// VerifyCommandContract function is sufficient; a CommandContract may be used
+// directly when the default settings are not appropriate.
+type CommandContract[TRequest any, TResult any] struct {
+	// Command is the command to be verified.
+	Command mediator.CommandHandler[TRequest, TResult]
+
+	// Requests are sample requests used to exercise the command.  At least one
+	// request should be valid; invalid requests exercise the Validator of the
+	// command (if implemented).
+	Requests []TRequest
+
+	// Concurrency is the number of goroutines concurrently executing each sample
+	// request when verifying that the command is safe for concurrent use; if
+	// zero, a default of 8 is used.
+	Concurrency int
+
+	// Timeout is the maximum time allowed for the command to respond to a
+	// cancelled context; if zero, a default of 5 seconds is used.
+	Timeout time.Duration
+
+	// IgnoreCancellation may be set true for a command which does not perform
+	// any operations that may be cancelled (e.g. a command that does not call
+	// any external service) to skip verification of context cancellation.
+	IgnoreCancellation bool
+
+	// Context is the context with which invalid sample requests are executed
+	// through the mediator, when verifying that validation errors surface as
+	// a ValidationError (e.g. a context with a principal, for a command which
+	// implements Authorizer, or a database, for a Transactional command); if
+	// nil, a background context is used.
+	Context context.Context
+}
+
+// VerifyCommandContract[TRequest, TResult] verifies that the specified command
+// behaves as the mediator relies on, using the specified sample requests.  It
+// is intended to be called from the tests of each command package:
+//
+//	func TestContract(t *testing.T) {
+//	    mediatortest.VerifyCommandContract(t, &Command{}, Request{Id: "1"}, Request{})
+//	}
+//
+// See CommandContract.Verify for the behaviour verified.
+func VerifyCommandContract[TRequest any, TResult any](t *testing.T, cmd mediator.CommandHandler[TRequest, TResult], requests ...TRequest) {
+	t.Helper()
+	CommandContract[TRequest, TResult]{Command: cmd, Requests: requests}.Verify(t)
+}
+
+// Verify runs the conformance suite as subtests of the specified test,
+// verifying that:
+//
+//   - CheckConfiguration (if implemented) is idempotent, returning the same
+//     result when called more than once;
+//   - Validate (if implemented) does not panic and returns the same result
+//     when called more than once with the same request;
+//   - errors returned by Validate (if implemented) surface as a
+//     ValidationError when invalid requests are executed through the
+//     mediator, and a Validate method is not ignored by the mediator because
+//     it does not implement mediator.Validator for the request type;
+//   - Execute responds to a cancelled context, returning promptly with an error
+//     wrapping context.Canceled;
+//   - the command may be called concurrently without panicking (run the
+//     tests with -race to also detect data races);
+//   - the command does not panic when called with a zero-value request.
+//
+// Other than when verifying validation errors, the command is called directly;
+// requests are not authorized or executed in a unit of work.
+func (c CommandContract[TRequest, TResult]) Verify(t *testing.T) {
+	t.Helper()
+
+	if c.Command == nil {
+		t.Fatal("invalid contract: no command")
+	}
+	if c.Concurrency == 0 {
+		c.Concurrency = 8
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 5 * time.Second
+	}
+
+	checks := []struct {
+		name  string
+		check func() (skip string, failures []string)
+	}{
+		{name: "CheckConfiguration is idempotent", check: c.verifyConfiguration},
+		{name: "Validate is deterministic", check: c.verifyValidation},
+		{name: "Validate errors surface as ValidationError", check: c.verifyValidationErrors},
+		{name: "Execute honours context cancellation", check: c.verifyCancellation},
+		{name: "safe for concurrent use", check: c.verifyConcurrency},
+		{name: "zero-value request does not panic", check: c.verifyZeroValue},
+	}
+	for _, each := range checks {
+		check := each.check
+		t.Run(each.name, func(t *testing.T) {
+			skip, failures := check()
+			if skip != "" {
+				t.Skip(skip)
+			}
+			for _, s := range failures {
+				t.Error(s)
+			}
+		})
+	}
+}
+
+// call validates and executes a request as the mediator would, recovering
+// any panic.  An error returned by the Validator of the command is returned
+// as a ValidationError.
+func (c CommandContract[TRequest, TResult]) call(ctx context.Context, rq TRequest) (panicked any, err error) {
+	defer func() { panicked = recover() }()
+	if v, ok := c.Command.(mediator.Validator[TRequest]); ok {
+		if err := v.Validate(ctx, rq); err != nil {
+			if _, ok := err.(mediator.ValidationError); !ok {
+				err = mediator.ValidationError{E: err}
+			}
+			return nil, err
+		}
+	}
+	_, err = c.Command.Execute(ctx, rq)
+	return nil, err
+}
+
+// valid returns the sample requests which pass validation.
+func (c CommandContract[TRequest, TResult]) valid() []TRequest {
+	v, ok := c.Command.(mediator.Validator[TRequest])
+	if !ok {
+		return c.Requests
+	}
+
+	result := []TRequest{}
+	for _, rq := range c.Requests {
+		if v.Validate(context.Background(), rq) == nil {
+			result = append(result, rq)
+		}
+	}
+	return result
+}
+
+// verifyConfiguration verifies that CheckConfiguration returns the same result
+// when called more than once.
+func (c CommandContract[TRequest, TResult]) verifyConfiguration() (string, []string) {
+	cfg, ok := c.Command.(mediator.ConfigurationChecker)
+	if !ok {
+		return "command does not implement ConfigurationChecker", nil
+	}
+
+	ctx := context.Background()
+	first := cfg.CheckConfiguration(ctx)
+	second := cfg.CheckConfiguration(ctx)
+
+	if fmt.Sprint(first) != fmt.Sprint(second) {
+		return "", []string{fmt.Sprintf("\nfirst call returned  %v\nsecond call returned %v", first, second)}
+	}
+	return "", nil
+}
+
+// verifyValidation verifies that Validate returns the same result when called
+// more than once with each sample request, without panicking.  The mediator
+// validates each request once only; a request must not pass or fail
+// validation depending on when it is validated.
+func (c CommandContract[TRequest, TResult]) verifyValidation() (string, []string) {
+	v, ok := c.Command.(mediator.Validator[TRequest])
+	if !ok {
+		return "command does not implement Validator", nil
+	}
+
+	validate := func(ctx context.Context, rq TRequest) (panicked any, err error) {
+		defer func() { panicked = recover() }()
+		return nil, v.Validate(ctx, rq)
+	}
+
+	ctx := context.Background()
+	failures := []string{}
+	for _, rq := range c.Requests {
+		panicked, first := validate(ctx, rq)
+		if panicked == nil {
+			var second error
+			if panicked, second = validate(ctx, rq); panicked == nil && fmt.Sprint(first) != fmt.Sprint(second) {
+				failures = append(failures, fmt.Sprintf("request %+v:\nfirst call returned  %v\nsecond call returned %v", rq, first, second))
+			}
+		}
+		if panicked != nil {
+			failures = append(failures, fmt.Sprintf("request %+v: panicked: %v", rq, panicked))
+		}
+	}
+	return "", failures
+}
+
+// verifyValidationErrors verifies that each sample request rejected by the
+// Validator of the command is rejected with a ValidationError wrapping the
+// error when executed through the mediator.  The command is registered in an
+// isolated registry, so that it is executed in place of any command registered
+// for the request type.
+func (c CommandContract[TRequest, TResult]) verifyValidationErrors() (string, []string) {
+	v, ok := c.Command.(mediator.Validator[TRequest])
+	if !ok {
+		if _, ok := reflect.TypeOf(c.Command).MethodByName("Validate"); ok {
+			return "", []string{fmt.Sprintf("%T has a Validate method which does not implement mediator.Validator[%v]; requests are not validated", c.Command, reflect.TypeOf((*TRequest)(nil)).Elem())}
+		}
+		return "command does not implement Validator", nil
+	}
+
+	validate := func(ctx context.Context, rq TRequest) (err error) {
+		defer func() {
+			if recover() != nil {
+				err = nil // reported by verifyValidation
+			}
+		}()
+		return v.Validate(ctx, rq)
+	}
+
+	ctx := c.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r := mediator.NewRegistry()
+	ctx = mediator.ContextWithRegistry(ctx, r)
+
+	failures := []string{}
+	invalid := 0
+	for _, rq := range c.Requests {
+		wanted := validate(ctx, rq)
+		if wanted == nil {
+			continue
+		}
+		if invalid == 0 {
+			unreg, err := mediator.RegisterCommandIn(ctx, r, c.Command)
+			if err != nil {
+				return "", []string{fmt.Sprintf("unable to register command: %v", err)}
+			}
+			defer unreg()
+		}
+		invalid++
+
+		if _, ok := wanted.(mediator.ValidationError); !ok {
+			wanted = mediator.ValidationError{E: wanted}
+		}
+		panicked, err := contractExecute[TRequest, TResult](ctx, rq)
+		switch {
+		case panicked != nil:
+			failures = append(failures, fmt.Sprintf("request %+v: panicked: %v", rq, panicked))
+		case !errors.As(err, new(mediator.ValidationError)) || err.Error() != wanted.Error():
+			failures = append(failures, fmt.Sprintf("request %+v:\nwanted %v\ngot    %v", rq, wanted, err))
+		}
+	}
+	if invalid == 0 {
+		return "no invalid sample requests", nil
+	}
+	return "", failures
+}
+
+// contractExecute executes a request through the mediator, recovering any
+// panic.
+func contractExecute[TRequest any, TResult any](ctx context.Context, rq TRequest) (panicked any, err error) {
+	defer func() { panicked = recover() }()
+	_, err = mediator.Execute(ctx, rq, new(TResult))
+	return nil, err
+}
+
+// verifyCancellation verifies that each valid sample request returns promptly
+// with an error wrapping context.Canceled when executed with a cancelled
+// context.
+func (c CommandContract[TRequest, TResult]) verifyCancellation() (string, []string) {
+	if c.IgnoreCancellation {
+		return "cancellation ignored", nil
+	}
+	requests := c.valid()
+	if len(requests) == 0 {
+		return "no valid sample requests", nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type outcome struct {
+		panicked any
+		err      error
+	}
+	failures := []string{}
+	for _, rq := range requests {
+		done := make(chan outcome, 1)
+		go func(rq TRequest) {
+			panicked, err := c.call(ctx, rq)
+			done <- outcome{panicked, err}
+		}(rq)
+
+		select {
+		case o := <-done:
+			switch {
+			case o.panicked != nil:
+				failures = append(failures, fmt.Sprintf("request %+v: panicked: %v", rq, o.panicked))
+			case !errors.Is(o.err, context.Canceled):
+				failures = append(failures, fmt.Sprintf("request %+v: with cancelled context:\nwanted error wrapping %v\ngot    %v", rq, context.Canceled, o.err))
+			}
+		case <-time.After(c.Timeout):
+			failures = append(failures, fmt.Sprintf("request %+v: did not return within %v of context cancellation", rq, c.Timeout))
+		}
+	}
+	return "", failures
+}
+
+// verifyConcurrency verifies that the sample requests may be executed
+// concurrently without panicking.
+func (c CommandContract[TRequest, TResult]) verifyConcurrency() (string, []string) {
+	if len(c.Requests) == 0 {
+		return "no sample requests", nil
+	}
+
+	ctx := context.Background()
+	mu := sync.Mutex{}
+	failures := []string{}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < c.Concurrency; i++ {
+		for _, rq := range c.Requests {
+			wg.Add(1)
+			go func(rq TRequest) {
+				defer wg.Done()
+				if panicked, _ := c.call(ctx, rq); panicked != nil {
+					mu.Lock()
+					failures = append(failures, fmt.Sprintf("request %+v: panicked: %v", rq, panicked))
+					mu.Unlock()
+				}
+			}(rq)
+		}
+	}
+	wg.Wait()
+
+	return "", failures
+}
+
+// verifyZeroValue verifies that a zero-value request does not panic.
+func (c CommandContract[TRequest, TResult]) verifyZeroValue() (string, []string) {
+	if panicked, _ := c.call(context.Background(), *new(TRequest)); panicked != nil {
+		return "", []string{fmt.Sprintf("panicked: %v", panicked)}
+	}
+	return "", nil
+}
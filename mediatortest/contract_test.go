@@ -0,0 +1,245 @@
+package mediatortest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blugnu/mediator"
+)
+
+// contracttestcmd is a command which satisfies the command contract.
+type contracttestcmd struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (cmd *contracttestcmd) CheckConfiguration(context.Context) error { return nil }
+
+func (cmd *contracttestcmd) Validate(_ context.Context, rq string) error {
+	if rq == "invalid" {
+		return errors.New("invalid request")
+	}
+	return nil
+}
+
+func (cmd *contracttestcmd) Execute(ctx context.Context, rq string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	cmd.calls++
+	return len(rq), nil
+}
+
+// contracttestbadcmd is a command which breaks the command contract.
+type contracttestbadcmd struct {
+	checked     bool
+	validations uint64
+}
+
+func (cmd *contracttestbadcmd) CheckConfiguration(context.Context) error {
+	if cmd.checked {
+		return errors.New("already checked")
+	}
+	cmd.checked = true
+	return nil
+}
+
+func (cmd *contracttestbadcmd) Validate(_ context.Context, rq *string) error {
+	switch *rq {
+	case "invalid":
+		return errors.New("invalid request")
+	case "flaky":
+		if atomic.AddUint64(&cmd.validations, 1)%2 == 0 {
+			return errors.New("invalid request")
+		}
+	case "panic":
+		panic("validation panicked")
+	}
+	return nil
+}
+
+func (cmd *contracttestbadcmd) Execute(ctx context.Context, rq *string) (int, error) {
+	if *rq == "slow" {
+		time.Sleep(50 * time.Millisecond)
+	}
+	return len(*rq), nil
+}
+
+// contracttestauthcmd is a command which satisfies the command contract and
+// implements Authorizer.
+type contracttestauthcmd struct {
+	contracttestcmd
+}
+
+func (cmd *contracttestauthcmd) Authorize(context.Context, any, string) error { return nil }
+
+// contracttestunvalidatedcmd is a command with a Validate method which does
+// not implement Validator for the request type of the command.
+type contracttestunvalidatedcmd struct{}
+
+func (contracttestunvalidatedcmd) Validate(context.Context, string) error { return nil }
+
+func (contracttestunvalidatedcmd) Execute(context.Context, *string) (int, error) { return 0, nil }
+
+func TestVerifyCommandContract(t *testing.T) {
+	VerifyCommandContract[string, int](t, &contracttestcmd{}, "valid", "invalid")
+}
+
+func TestCommandContractFailures(t *testing.T) {
+	// ARRANGE
+	valid := "valid"
+	slow := "slow"
+	invalid := "invalid"
+	sut := CommandContract[*string, int]{
+		Command:     &contracttestbadcmd{},
+		Requests:    []*string{&valid, &invalid, &slow},
+		Concurrency: 2,
+		Timeout:     10 * time.Millisecond,
+	}
+
+	t.Run("CheckConfiguration is not idempotent", func(t *testing.T) {
+		// ACT
+		_, failures := sut.verifyConfiguration()
+
+		// ASSERT
+		wanted := []string{"\nfirst call returned  <nil>\nsecond call returned already checked"}
+		got := failures
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Execute does not honour cancellation", func(t *testing.T) {
+		// ACT
+		_, failures := sut.verifyCancellation()
+
+		// ASSERT
+		wanted := 2
+		got := len(failures)
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v (%v)", wanted, got, failures)
+		}
+	})
+
+	t.Run("zero-value request panics", func(t *testing.T) {
+		// ACT
+		_, failures := sut.verifyZeroValue()
+
+		// ASSERT
+		wanted := 1
+		got := len(failures)
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Validate is not deterministic", func(t *testing.T) {
+		// ARRANGE
+		flaky := "flaky"
+		panics := "panic"
+		sut := sut
+		sut.Requests = []*string{&valid, &invalid, &flaky, &panics}
+
+		// ACT
+		_, failures := sut.verifyValidation()
+
+		// ASSERT
+		wanted := []string{
+			fmt.Sprintf("request %p:\nfirst call returned  <nil>\nsecond call returned invalid request", &flaky),
+			fmt.Sprintf("request %p: panicked: validation panicked", &panics),
+		}
+		got := failures
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Validate errors do not surface as ValidationError", func(t *testing.T) {
+		// ARRANGE
+		sut := CommandContract[string, int]{
+			Command:  &contracttestauthcmd{},
+			Requests: []string{"valid", "invalid"},
+		}
+
+		// ACT
+		_, failures := sut.verifyValidationErrors()
+
+		// ASSERT
+		wanted := []string{"request invalid:\nwanted request validation error: invalid request\ngot    unauthorized: no principal"}
+		got := failures
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+
+		t.Run("with context", func(t *testing.T) {
+			// ARRANGE
+			sut := sut
+			sut.Context = mediator.ContextWithPrincipal(context.Background(), "user")
+
+			// ACT
+			_, failures := sut.verifyValidationErrors()
+
+			// ASSERT
+			if len(failures) > 0 {
+				t.Errorf("unexpected failures: %v", failures)
+			}
+		})
+	})
+
+	t.Run("Validate does not implement Validator", func(t *testing.T) {
+		// ARRANGE
+		sut := CommandContract[*string, int]{
+			Command:  contracttestunvalidatedcmd{},
+			Requests: []*string{&invalid},
+		}
+
+		// ACT
+		_, failures := sut.verifyValidationErrors()
+
+		// ASSERT
+		wanted := []string{"mediatortest.contracttestunvalidatedcmd has a Validate method which does not implement mediator.Validator[*string]; requests are not validated"}
+		got := failures
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when there are no invalid requests", func(t *testing.T) {
+		// ARRANGE
+		sut := CommandContract[string, int]{Command: &contracttestcmd{}, Requests: []string{"valid"}}
+
+		// ACT
+		skip, _ := sut.verifyValidationErrors()
+
+		// ASSERT
+		wanted := "no invalid sample requests"
+		got := skip
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when cancellation is ignored", func(t *testing.T) {
+		// ARRANGE
+		sut := sut
+		sut.IgnoreCancellation = true
+
+		// ACT
+		skip, _ := sut.verifyCancellation()
+
+		// ASSERT
+		wanted := "cancellation ignored"
+		got := skip
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package mediatortest
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// FuzzCommand[TRequest, TResult] plugs the command registered for the request
+// type into Go native fuzzing.  Requests are generated from the fuzz input by
+// setting the exported fields of the request (recursively) from successive
+// bytes of the input, and executed through the mediator.
+//
+//	func FuzzGetFoo(f *testing.F) {
+//	    _ = mediator.RegisterCommand[getFoo.Request, *getFoo.Result](ctx, &getFoo.Command{})
+//
+//	    mediatortest.FuzzCommand[getFoo.Request, *getFoo.Result](f, getFoo.ErrNotFound)
+//	}
+//
+// The fuzz test fails if the command panics or returns an error which is
+// neither a ValidationError nor one of the specified handler errors (matched
+// using errors.Is).
+//
+// Requests are executed using a background context; to execute requests using
+// a context with a principal, metadata or a database (e.g. for commands
+// subject to policies, with an Authorizer, executed in a unit of work or with
+// tenant overrides) use FuzzCommandWithContext.
+//
+// If no command is registered for the request type, or the registered command
+// does not return the specified result type, the fuzz test fails immediately.
+func FuzzCommand[TRequest any, TResult any](f *testing.F, handlerErrors ...error) {
+	f.Helper()
+
+	FuzzCommandWithContext[TRequest, TResult](f, func(testing.TB) context.Context { return context.Background() }, handlerErrors...)
+}
+
+// FuzzCommandWithContext[TRequest, TResult] plugs the command registered for
+// the request type into Go native fuzzing (see FuzzCommand), executing each
+// request using the context returned by the specified function, which is
+// called with the test of each fuzz input:
+//
+//	func FuzzGetFoo(f *testing.F) {
+//	    _ = mediator.RegisterCommand[getFoo.Request, *getFoo.Result](ctx, &getFoo.Command{})
+//
+//	    mediatortest.FuzzCommandWithContext[getFoo.Request, *getFoo.Result](f, func(t testing.TB) context.Context {
+//	        return mediator.ContextWithPrincipal(mediatortest.TestContext(t), user)
+//	    }, getFoo.ErrNotFound)
+//	}
+//
+// The command must be registered in the global registry, even if requests are
+// executed by a command registered for a tenant (or in a test registry) bound
+// to the context.
+func FuzzCommandWithContext[TRequest any, TResult any](f *testing.F, ctx func(testing.TB) context.Context, handlerErrors ...error) {
+	f.Helper()
+
+	info, err := mediator.ResolveCommand(reflect.TypeOf(*new(TRequest)))
+	if err != nil {
+		f.Fatalf("unable to fuzz: %v", err)
+	}
+	if rt := mediator.TypeName(reflect.TypeOf((*TResult)(nil)).Elem()); info.ResultType != rt {
+		f.Fatalf("unable to fuzz: %s does not return %s (returns: %s)", info.HandlerType, rt, info.ResultType)
+	}
+
+	f.Add([]byte{})
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rq := FuzzRequest[TRequest](data)
+
+		panicked, err := fuzzExecute[TRequest, TResult](ctx(t), rq)
+		if failure := fuzzFailure(panicked, err, handlerErrors); failure != "" {
+			t.Errorf("request %+v: %s", rq, failure)
+		}
+	})
+}
+
+// fuzzExecute executes a request through the mediator, recovering any panic.
+func fuzzExecute[TRequest any, TResult any](ctx context.Context, rq TRequest) (panicked any, err error) {
+	defer func() { panicked = recover() }()
+	_, err = mediator.Execute(ctx, rq, new(TResult))
+	return nil, err
+}
+
+// fuzzFailure returns a description of the failure of a fuzzed request, or an
+// empty string if the request did not fail.
+func fuzzFailure(panicked any, err error, handlerErrors []error) string {
+	if panicked != nil {
+		return fmt.Sprintf("panicked: %v", panicked)
+	}
+	if err == nil || errors.As(err, new(mediator.ValidationError)) {
+		return ""
+	}
+	for _, herr := range handlerErrors {
+		if errors.Is(err, herr) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("unexpected error: %v", err)
+}
+
+// FuzzRequest[TRequest] returns a request generated from the specified fuzz
+// input.  The same input always generates the same request.  Exported fields
+// of structs are set recursively; unexported fields, functions, channels and
+// interfaces are left as zero values.  When the input is exhausted any
+// remaining fields are left as zero values.
+func FuzzRequest[TRequest any](data []byte) TRequest {
+	rq := new(TRequest)
+	d := &fuzzDecoder{data: data}
+	d.fill(reflect.ValueOf(rq).Elem(), 0)
+	return *rq
+}
+
+// fuzzMaxDepth limits the depth of recursion when generating a request, so
+// that recursive types are generated with finite depth.
+const fuzzMaxDepth = 8
+
+// fuzzDecoder generates values from a fuzz input.
+type fuzzDecoder struct {
+	data []byte
+}
+
+// bytes returns the next n bytes of the input (or fewer, if the input is
+// exhausted).
+func (d *fuzzDecoder) bytes(n int) []byte {
+	if n > len(d.data) {
+		n = len(d.data)
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+// uint64 returns an unsigned integer from the next n bytes of the input.
+func (d *fuzzDecoder) uint64(n int) uint64 {
+	b := make([]byte, 8)
+	copy(b, d.bytes(n))
+	return binary.LittleEndian.Uint64(b)
+}
+
+// length returns a length from the next byte of the input, no greater than
+// the specified limit.
+func (d *fuzzDecoder) length(limit int) int {
+	return int(d.uint64(1)) % (limit + 1)
+}
+
+// fill sets the specified value from the input.
+func (d *fuzzDecoder) fill(v reflect.Value, depth int) {
+	if len(d.data) == 0 || depth > fuzzMaxDepth {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(d.uint64(1)&1 == 1)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(d.uint64(int(v.Type().Size()))))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(d.uint64(int(v.Type().Size())))
+
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(uint32(d.uint64(4)))))
+
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(d.uint64(8)))
+
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(math.Float64frombits(d.uint64(8)), math.Float64frombits(d.uint64(8))))
+
+	case reflect.String:
+		v.SetString(string(d.bytes(d.length(255))))
+
+	case reflect.Slice:
+		n := d.length(8)
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			n = d.length(255)
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			d.fill(v.Index(i), depth+1)
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			d.fill(v.Index(i), depth+1)
+		}
+
+	case reflect.Map:
+		n := d.length(4)
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+		for i := 0; i < n; i++ {
+			k := reflect.New(v.Type().Key()).Elem()
+			e := reflect.New(v.Type().Elem()).Elem()
+			d.fill(k, depth+1)
+			d.fill(e, depth+1)
+			v.SetMapIndex(k, e)
+		}
+
+	case reflect.Pointer:
+		if d.uint64(1)&1 == 0 {
+			return
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		d.fill(v.Elem(), depth+1)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				d.fill(v.Field(i), depth+1)
+			}
+		}
+	}
+}
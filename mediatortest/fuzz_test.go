@@ -0,0 +1,136 @@
+package mediatortest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// fuzztestrequest is a request used for testing fuzzing.
+type fuzztestrequest struct {
+	Flag    bool
+	Count   int16
+	Name    string
+	Tags    []string
+	Next    *fuzztestrequest
+	private int
+}
+
+// fuzztestcmd is a command used for testing fuzzing.
+type fuzztestcmd struct{}
+
+var errFuzzTestNotFound = errors.New("not found")
+
+func (fuzztestcmd) Validate(_ context.Context, rq fuzztestrequest) error {
+	if rq.Count < 0 {
+		return errors.New("count must not be negative")
+	}
+	return nil
+}
+
+func (fuzztestcmd) Execute(_ context.Context, rq fuzztestrequest) (int, error) {
+	if rq.Name == "" {
+		return 0, errFuzzTestNotFound
+	}
+	return len(rq.Tags), nil
+}
+
+// fuzztestauthcmd is a command implementing Authorizer used for testing
+// fuzzing with a context.
+type fuzztestauthcmd struct {
+	fuzztestcmd
+}
+
+func (fuzztestauthcmd) Authorize(_ context.Context, principal any, _ fuzztestrequest) error {
+	if principal != "fuzzer" {
+		return errors.New("not authorized")
+	}
+	return nil
+}
+
+func FuzzFuzzCommand(f *testing.F) {
+	f.Cleanup(mediator.RegisterMockCommand[fuzztestrequest, int](context.Background(), fuzztestcmd{}))
+
+	f.Add([]byte{1, 2, 0, 3, 'f', 'o', 'o', 1, 3, 'b', 'a', 'r'})
+
+	FuzzCommand[fuzztestrequest, int](f, errFuzzTestNotFound)
+}
+
+func FuzzFuzzCommandWithContext(f *testing.F) {
+	f.Cleanup(mediator.RegisterMockCommand[fuzztestrequest, int](context.Background(), fuzztestauthcmd{}))
+
+	f.Add([]byte{1, 2, 0, 3, 'f', 'o', 'o', 1, 3, 'b', 'a', 'r'})
+
+	FuzzCommandWithContext[fuzztestrequest, int](f, func(t testing.TB) context.Context {
+		return mediator.ContextWithPrincipal(TestContext(t), "fuzzer")
+	}, errFuzzTestNotFound)
+}
+
+func TestFuzzRequest(t *testing.T) {
+	testcases := []struct {
+		name   string
+		data   []byte
+		result fuzztestrequest
+	}{
+		{name: "empty input", data: nil, result: fuzztestrequest{}},
+		{name: "partial input", data: []byte{1, 0x01, 0x02}, result: fuzztestrequest{Flag: true, Count: 0x0201}},
+		{name: "full input", data: []byte{
+			0,          // Flag
+			0xff, 0xff, // Count
+			3, 'f', 'o', 'o', // Name
+			2, 1, 'a', 0, // Tags
+			1, 1, // Next (non-nil), Next.Flag
+		}, result: fuzztestrequest{
+			Count: -1,
+			Name:  "foo",
+			Tags:  []string{"a", ""},
+			Next:  &fuzztestrequest{Flag: true},
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ACT
+			got := FuzzRequest[fuzztestrequest](tc.data)
+
+			// ASSERT
+			wanted := tc.result
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+}
+
+func TestFuzzFailure(t *testing.T) {
+	// ARRANGE
+	herr := errors.New("handler error")
+
+	testcases := []struct {
+		name     string
+		panicked any
+		err      error
+		result   string
+	}{
+		{name: "no error", result: ""},
+		{name: "validation error", err: mediator.ValidationError{E: errors.New("invalid")}, result: ""},
+		{name: "handler error", err: herr, result: ""},
+		{name: "wrapped handler error", err: mediator.ForbiddenError{E: herr}, result: ""},
+		{name: "other error", err: errors.New("other"), result: "unexpected error: other"},
+		{name: "panic", panicked: "boom", result: "panicked: boom"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ACT
+			got := fuzzFailure(tc.panicked, tc.err, []error{herr})
+
+			// ASSERT
+			wanted := tc.result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package mediatortest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// MockCommandT registers a mock command for the specified request and result type
+// in the isolated registry of the specified test, modelling successful execution
+// of the command returning a zero-value result and no error.
+//
+// The mock is called only for requests executed using a context obtained from
+// TestContext (or ContextWithTestRegistry) for the same test, and is unregistered
+// automatically when the test completes.
+func MockCommandT[TRequest any, TResult any](t testing.TB) *mediator.Mock[TRequest, TResult] {
+	t.Helper()
+	return MockCommandResultT[TRequest](t, *new(TResult))
+}
+
+// MockCommandErrorT registers a mock command for the specified request and result
+// type in the isolated registry of the specified test, modelling a failed execution
+// of the command, returning the specified error and a zero-value result.
+//
+// See MockCommandT.
+func MockCommandErrorT[TRequest any, TResult any](t testing.TB, err error) *mediator.Mock[TRequest, TResult] {
+	t.Helper()
+	return MockCommandFuncT[TRequest](t, nil, func(ctx context.Context, rq TRequest) (TResult, error) { return *new(TResult), err })
+}
+
+// MockCommandResultT registers a mock command for the specified request and result
+// type in the isolated registry of the specified test, modelling successful execution
+// of the command returning the specified result and a nil error.
+//
+// See MockCommandT.
+func MockCommandResultT[TRequest any, TResult any](t testing.TB, result TResult) *mediator.Mock[TRequest, TResult] {
+	t.Helper()
+	return MockCommandFuncT[TRequest](t, nil, func(ctx context.Context, rq TRequest) (TResult, error) { return result, nil })
+}
+
+// MockCommandValidationErrorT registers a mock command for the specified request
+// and result type in the isolated registry of the specified test, modelling a failed
+// validation of the request.
+//
+// See MockCommandT and mediator.MockCommandValidationError.
+func MockCommandValidationErrorT[TRequest any, TResult any](t testing.TB, err error) *mediator.Mock[TRequest, TResult] {
+	t.Helper()
+	return MockCommandFuncT[TRequest, TResult](t, func(context.Context, TRequest) error { return err }, nil)
+}
+
+// MockCommandFuncT registers a mock command for the specified request and result
+// type in the isolated registry of the specified test, which validates requests
+// using the specified validation function and executes them using the specified
+// execution function.
+//
+// If a command is already registered for the request type in the registry of
+// the test, the test fails.
+//
+// See MockCommandT and mediator.MockCommandFunc.
+func MockCommandFuncT[TRequest any, TResult any](t testing.TB, validate func(context.Context, TRequest) error, execute func(context.Context, TRequest) (TResult, error)) *mediator.Mock[TRequest, TResult] {
+	t.Helper()
+	mock, err := mediator.MockCommandFuncIn(registry(t), validate, execute)
+	if err != nil {
+		t.Fatalf("unable to register mock: %v", err)
+		return nil
+	}
+	t.Cleanup(mock.Unregister)
+	return mock
+}
+
+// RegisterMockCommandT registers a custom mock command in the isolated registry of
+// the specified test.  The mock is unregistered automatically when the test
+// completes.
+//
+// If the mock cannot be registered (e.g. the mock returns an error from
+// CheckConfiguration), the test fails.
+//
+// See MockCommandT and mediator.RegisterMockCommand.
+func RegisterMockCommandT[TRequest any, TResult any](t testing.TB, mock mediator.CommandHandler[TRequest, TResult]) {
+	t.Helper()
+	unreg, err := mediator.RegisterCommandIn(context.Background(), registry(t), mock)
+	if err != nil {
+		t.Fatalf("unable to register mock: %v", err)
+		return
+	}
+	t.Cleanup(unreg)
+}
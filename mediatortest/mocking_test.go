@@ -0,0 +1,103 @@
+package mediatortest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// registermocktestcmd is a custom mock command used for testing
+// RegisterMockCommandT.
+type registermocktestcmd struct {
+	cfgerr error
+}
+
+func (cmd *registermocktestcmd) CheckConfiguration(context.Context) error { return cmd.cfgerr }
+func (*registermocktestcmd) Execute(context.Context, int) (mediator.NoResultType, error) {
+	return nil, nil
+}
+
+func TestMockCommandT(t *testing.T) {
+	t.Run("MockCommandErrorT", func(t *testing.T) {
+		// ARRANGE
+		ctx := TestContext(t)
+		herr := errors.New("command error")
+		_ = MockCommandErrorT[string, mediator.NoResultType](t, herr)
+
+		// ACT
+		_, err := mediator.Execute(ctx, "request", mediator.NoResult)
+
+		// ASSERT
+		wanted := herr
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("MockCommandValidationErrorT", func(t *testing.T) {
+		// ARRANGE
+		ctx := TestContext(t)
+		verr := errors.New("validation error")
+		_ = MockCommandValidationErrorT[string, mediator.NoResultType](t, verr)
+
+		// ACT
+		_, err := mediator.Execute(ctx, "request", mediator.NoResult)
+
+		// ASSERT
+		wanted := mediator.ValidationError{E: verr}
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("RegisterMockCommandT", func(t *testing.T) {
+		// ARRANGE
+		ctx := TestContext(t)
+		cmd := &registermocktestcmd{}
+		RegisterMockCommandT[int, mediator.NoResultType](t, cmd)
+
+		// ACT
+		_, err := mediator.Execute(ctx, 1, mediator.NoResult)
+
+		// ASSERT
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Unregister removes mock from test registry", func(t *testing.T) {
+		// ARRANGE
+		ctx := TestContext(t)
+		mock := MockCommandT[string, mediator.NoResultType](t)
+
+		// ACT
+		mock.Unregister()
+
+		// ASSERT
+		_, err := mediator.Execute(ctx, "request", mediator.NoResult)
+		if !errors.As(err, new(*mediator.NoCommandForRequestTypeError)) {
+			t.Errorf("wanted NoCommandForRequestTypeError, got %v", err)
+		}
+	})
+
+	t.Run("when mock cannot be registered", func(t *testing.T) {
+		// ARRANGE
+		tb := &faketb{}
+		cfgerr := errors.New("configuration error")
+
+		// ACT
+		RegisterMockCommandT[int, mediator.NoResultType](tb, &registermocktestcmd{cfgerr: cfgerr})
+
+		// ASSERT
+		wanted := []string{"unable to register mock: configuration error"}
+		got := tb.errors
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
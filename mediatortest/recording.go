@@ -0,0 +1,314 @@
+package mediatortest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// goldenFileVersion is the version of the format of golden files written by
+// Record.
+const goldenFileVersion = 1
+
+// goldenFile is the content of a golden file of recorded interactions.
+type goldenFile struct {
+	Version      int           `json:"version"`
+	Interactions []interaction `json:"interactions"`
+}
+
+// interaction is a recorded request and the result and error returned by
+// the command.
+type interaction struct {
+	RequestType string          `json:"requestType"`
+	Request     json.RawMessage `json:"request"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       *recordedError  `json:"error,omitempty"`
+}
+
+// recordedError is an error recorded in an interaction.
+type recordedError struct {
+	Message    string `json:"message"`
+	Validation bool   `json:"validation,omitempty"` // true if the error was returned by the Validator of the command
+}
+
+// key returns the key identifying an interaction in a golden file.
+func (i interaction) key() string {
+	return i.RequestType + " " + string(i.Request)
+}
+
+// goldenFiles serializes the reading and writing of golden files by tests
+// recording interactions.
+var goldenFiles sync.Mutex
+
+// readGoldenFile reads the interactions recorded in the specified golden file.
+// If the file does not exist, an empty golden file is returned.
+func readGoldenFile(path string) (goldenFile, error) {
+	gf := goldenFile{Version: goldenFileVersion}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return gf, nil
+	}
+	if err != nil {
+		return gf, err
+	}
+
+	if err := json.Unmarshal(b, &gf); err != nil {
+		return gf, fmt.Errorf("%s: %w", path, err)
+	}
+	if gf.Version != goldenFileVersion {
+		return gf, fmt.Errorf("%s: unsupported golden file version: %d", path, gf.Version)
+	}
+
+	// requests are indented in the file; the key of an interaction is
+	// the compact encoding of the request
+	for i := range gf.Interactions {
+		buf := &bytes.Buffer{}
+		if err := json.Compact(buf, gf.Interactions[i].Request); err != nil {
+			return gf, fmt.Errorf("%s: %w", path, err)
+		}
+		gf.Interactions[i].Request = buf.Bytes()
+	}
+	return gf, nil
+}
+
+// writeGoldenFile writes the specified interactions to a golden file, sorted
+// by request type and request.
+func writeGoldenFile(path string, gf goldenFile) error {
+	sort.Slice(gf.Interactions, func(i, j int) bool {
+		return gf.Interactions[i].key() < gf.Interactions[j].key()
+	})
+
+	b, err := json.MarshalIndent(gf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// Record[TRequest, TResult] records the interactions with the command
+// registered for the request type in the global registry, writing them to
+// the specified golden file when the test completes.  The real command is
+// called for every request (see Spy).
+//
+//	func TestWithRealCommands(t *testing.T) {
+//	    mediatortest.Record[getFoo.Request, *getFoo.Result](t, "testdata/foo.golden.json")
+//	    ...
+//	}
+//
+// Interactions are identified by the request type and the JSON encoding of the
+// request.  Any interactions previously recorded in the file for the request
+// type are replaced; interactions for other request types are preserved, so
+// a golden file may hold the interactions for a number of request types.  If
+// the same request is executed more than once, the last interaction is
+// recorded.
+//
+// Interactions are not recorded if the test fails.
+//
+// As with Spy, Record must not be used in tests running in parallel with other
+// tests that use the global registry.
+func Record[TRequest any, TResult any](t testing.TB, path string) {
+	t.Helper()
+
+	sp := Spy[TRequest, TResult](t)
+	if sp == nil {
+		return
+	}
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("interactions not recorded in %s: test failed", path)
+			return
+		}
+		if err := writeInteractions(path, sp.Calls()); err != nil {
+			t.Errorf("unable to record interactions: %v", err)
+		}
+	})
+}
+
+// writeInteractions writes the specified calls as interactions to a golden
+// file, replacing any interactions previously recorded for the request type.
+func writeInteractions[TRequest any, TResult any](path string, calls []SpyCall[TRequest, TResult]) error {
+	goldenFiles.Lock()
+	defer goldenFiles.Unlock()
+
+	gf, err := readGoldenFile(path)
+	if err != nil {
+		return err
+	}
+
+	rqt := mediator.TypeName(reflect.TypeOf(*new(TRequest)))
+	recorded := map[string]interaction{}
+	for _, i := range gf.Interactions {
+		if i.RequestType != rqt {
+			recorded[i.key()] = i
+		}
+	}
+
+	for _, call := range calls {
+		// a request validated successfully but not executed (e.g. because
+		// the command panicked) has no outcome to record
+		if call.ValidationError == nil && !call.Executed {
+			continue
+		}
+
+		i := interaction{RequestType: rqt}
+		if i.Request, err = json.Marshal(call.Request); err != nil {
+			return err
+		}
+		switch {
+		case call.ValidationError != nil:
+			i.Error = &recordedError{Message: call.ValidationError.Error(), Validation: true}
+		case call.Error != nil:
+			i.Error = &recordedError{Message: call.Error.Error()}
+		default:
+			if i.Result, err = json.Marshal(call.Result); err != nil {
+				return err
+			}
+		}
+		recorded[i.key()] = i
+	}
+
+	gf.Interactions = make([]interaction, 0, len(recorded))
+	for _, i := range recorded {
+		gf.Interactions = append(gf.Interactions, i)
+	}
+	return writeGoldenFile(path, gf)
+}
+
+// ReplayedError is returned by a replay command when replaying an interaction
+// in which the command returned an error.  The error identifies only the
+// message of the original error.
+type ReplayedError struct {
+	Message string
+}
+
+func (e ReplayedError) Error() string {
+	return e.Message
+}
+
+// UnrecordedRequestError is returned by a replay command when it receives a
+// request for which no interaction was recorded.
+type UnrecordedRequestError struct {
+	Path    string // the golden file
+	Request string // the JSON encoding of the request
+	Type    string // the request type
+}
+
+func (e UnrecordedRequestError) Error() string {
+	return fmt.Sprintf("%s: no interaction recorded for %s request: %s", e.Path, e.Type, e.Request)
+}
+
+// replay[TRequest, TResult] is a command which replays interactions recorded
+// in a golden file.
+type replay[TRequest any, TResult any] struct {
+	t            testing.TB
+	path         string
+	interactions map[string]interaction
+}
+
+// Replay[TRequest, TResult] registers a command for the request type in the
+// isolated registry of the specified test (see TestContext), which replays the
+// interactions for the request type recorded (by Record) in the specified
+// golden file.  Requests executed using a context bound to the registry of the
+// test are sent to the replay command rather than any command registered in
+// the global registry.
+//
+//	func TestWithRecordedCommands(t *testing.T) {
+//	    mediatortest.Replay[getFoo.Request, *getFoo.Result](t, "testdata/foo.golden.json")
+//	    ...
+//	}
+//
+// Requests are matched to interactions by their JSON encoding.  An error
+// recorded from the Validator of the command is returned by the Validator of
+// the replay command (and will therefore be wrapped in a ValidationError); any
+// other recorded error is returned as a ReplayedError.
+//
+// If a request is received for which no interaction was recorded the test
+// fails and an UnrecordedRequestError is returned.  If the golden file cannot
+// be read, the test fails.
+func Replay[TRequest any, TResult any](t testing.TB, path string) {
+	t.Helper()
+
+	gf, err := readGoldenFile(path)
+	if err != nil {
+		t.Fatalf("unable to replay interactions: %v", err)
+		return
+	}
+
+	rqt := reflect.TypeOf(*new(TRequest))
+	cmd := &replay[TRequest, TResult]{t: t, path: path, interactions: map[string]interaction{}}
+	for _, i := range gf.Interactions {
+		if i.RequestType == mediator.TypeName(rqt) {
+			cmd.interactions[i.key()] = i
+		}
+	}
+
+	unreg, err := mediator.RegisterCommandIn[TRequest, TResult](context.Background(), registry(t), cmd)
+	if err != nil {
+		t.Fatalf("unable to replay interactions: %v", err)
+		return
+	}
+	t.Cleanup(unreg)
+}
+
+// interaction returns the interaction recorded for the specified request.
+func (cmd *replay[TRequest, TResult]) interaction(rq TRequest) (interaction, error) {
+	i := interaction{RequestType: mediator.TypeName(reflect.TypeOf(rq))}
+
+	var err error
+	if i.Request, err = json.Marshal(rq); err != nil {
+		return i, err
+	}
+
+	recorded, ok := cmd.interactions[i.key()]
+	if !ok {
+		return i, UnrecordedRequestError{Path: cmd.path, Request: string(i.Request), Type: i.RequestType}
+	}
+	return recorded, nil
+}
+
+// Validate satisfies the Validator interface, returning any validation error
+// recorded for the request.
+func (cmd *replay[TRequest, TResult]) Validate(_ context.Context, rq TRequest) error {
+	i, err := cmd.interaction(rq)
+	if err != nil {
+		return nil // the request is rejected by Execute
+	}
+	if i.Error != nil && i.Error.Validation {
+		return errors.New(i.Error.Message)
+	}
+	return nil
+}
+
+// Execute satisfies the CommandHandler interface, returning the result or
+// error recorded for the request.
+func (cmd *replay[TRequest, TResult]) Execute(_ context.Context, rq TRequest) (TResult, error) {
+	z := *new(TResult)
+
+	i, err := cmd.interaction(rq)
+	if err != nil {
+		cmd.t.Errorf("%v", err)
+		return z, err
+	}
+	if i.Error != nil {
+		return z, ReplayedError{Message: i.Error.Message}
+	}
+
+	result := new(TResult)
+	if len(i.Result) > 0 {
+		if err := json.Unmarshal(i.Result, result); err != nil {
+			return z, fmt.Errorf("%s: %w", cmd.path, err)
+		}
+	}
+	return *result, nil
+}
@@ -0,0 +1,190 @@
+package mediatortest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// recordingtestrequest is a request used for testing recording and replay.
+type recordingtestrequest struct {
+	Id string
+}
+
+// recordingtestresult is a result used for testing recording and replay.
+type recordingtestresult struct {
+	Name string
+}
+
+// recordingtestcmd is a command used for testing recording and replay.
+type recordingtestcmd struct{}
+
+func (recordingtestcmd) Validate(_ context.Context, rq recordingtestrequest) error {
+	if rq.Id == "" {
+		return errors.New("id is required")
+	}
+	return nil
+}
+
+func (recordingtestcmd) Execute(_ context.Context, rq recordingtestrequest) (*recordingtestresult, error) {
+	if rq.Id == "missing" {
+		return nil, errors.New("not found")
+	}
+	return &recordingtestresult{Name: "name of " + rq.Id}, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "interactions.golden.json")
+	requests := []recordingtestrequest{{Id: "1"}, {Id: "missing"}, {}}
+
+	type outcome struct {
+		result *recordingtestresult
+		err    string
+	}
+	execute := func(ctx context.Context) []outcome {
+		result := []outcome{}
+		for _, rq := range requests {
+			r, err := mediator.Execute(ctx, rq, new(*recordingtestresult))
+			o := outcome{result: r}
+			if err != nil {
+				o.err = err.Error()
+			}
+			result = append(result, o)
+		}
+		return result
+	}
+
+	var recorded []outcome
+	t.Run("record", func(t *testing.T) {
+		// ARRANGE
+		t.Cleanup(mediator.RegisterMockCommand[recordingtestrequest, *recordingtestresult](ctx, recordingtestcmd{}))
+
+		t.Run("records interactions", func(t *testing.T) {
+			Record[recordingtestrequest, *recordingtestresult](t, path)
+
+			// ACT
+			recorded = execute(ctx)
+		})
+	})
+
+	t.Run("writes golden file", func(t *testing.T) {
+		// ARRANGE
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		wanted := strings.Join([]string{
+			`{`,
+			`  "version": 1,`,
+			`  "interactions": [`,
+			`    {`,
+			`      "requestType": "github.com/blugnu/mediator/mediatortest.recordingtestrequest",`,
+			`      "request": {`,
+			`        "Id": ""`,
+			`      },`,
+			`      "error": {`,
+			`        "message": "id is required",`,
+			`        "validation": true`,
+			`      }`,
+			`    },`,
+			`    {`,
+			`      "requestType": "github.com/blugnu/mediator/mediatortest.recordingtestrequest",`,
+			`      "request": {`,
+			`        "Id": "1"`,
+			`      },`,
+			`      "result": {`,
+			`        "Name": "name of 1"`,
+			`      }`,
+			`    },`,
+			`    {`,
+			`      "requestType": "github.com/blugnu/mediator/mediatortest.recordingtestrequest",`,
+			`      "request": {`,
+			`        "Id": "missing"`,
+			`      },`,
+			`      "error": {`,
+			`        "message": "not found"`,
+			`      }`,
+			`    }`,
+			`  ]`,
+			`}`,
+			``,
+		}, "\n")
+		got := string(b)
+		if wanted != got {
+			t.Errorf("\nwanted %s\ngot    %s", wanted, got)
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		// ARRANGE
+		Replay[recordingtestrequest, *recordingtestresult](t, path)
+
+		// ACT
+		replayed := execute(TestContext(t))
+
+		// ASSERT
+		wanted := recorded
+		got := replayed
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("replay of unrecorded request", func(t *testing.T) {
+		// ARRANGE
+		tb := &faketb{}
+		Replay[recordingtestrequest, *recordingtestresult](tb, path)
+		defer func() {
+			for _, fn := range tb.cleanups {
+				fn()
+			}
+		}()
+
+		// ACT
+		_, err := mediator.Execute(ContextWithTestRegistry(ctx, tb), recordingtestrequest{Id: "2"}, new(*recordingtestresult))
+
+		// ASSERT
+		wanted := UnrecordedRequestError{Path: path, Request: `{"Id":"2"}`, Type: "github.com/blugnu/mediator/mediatortest.recordingtestrequest"}
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+		if !reflect.DeepEqual(tb.errors, []string{wanted.Error()}) {
+			t.Errorf("wanted test failure, got %v", tb.errors)
+		}
+	})
+
+	t.Run("does not register replay globally", func(t *testing.T) {
+		_, err := mediator.ResolveCommand(reflect.TypeOf(recordingtestrequest{}))
+		if !errors.As(err, new(*mediator.NoCommandForRequestTypeError)) {
+			t.Errorf("wanted NoCommandForRequestTypeError, got %v", err)
+		}
+	})
+}
+
+func TestReplayWithInvalidGoldenFile(t *testing.T) {
+	// ARRANGE
+	path := filepath.Join(t.TempDir(), "invalid.golden.json")
+	_ = os.WriteFile(path, []byte(`{"version":2}`), 0o600)
+	tb := &faketb{}
+
+	// ACT
+	Replay[recordingtestrequest, *recordingtestresult](tb, path)
+
+	// ASSERT
+	wanted := []string{"unable to replay interactions: " + path + ": unsupported golden file version: 2"}
+	got := tb.errors
+	if !reflect.DeepEqual(wanted, got) {
+		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+	}
+}
@@ -0,0 +1,75 @@
+// Package mediatortest provides helpers for testing code which uses the
+// mediator: test-scoped mocks registered in an isolated registry for each
+// test, spies on real commands, recording and replay of interactions with
+// commands, a command contract conformance suite and fuzzing of commands.
+//
+// The mock factories which register mocks in the global registry (e.g.
+// mediator.MockCommand) are provided by the mediator package itself.
+package mediatortest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// registries holds the isolated registry of each test that has used a
+// test-scoped mock factory or TestContext.
+var registries = struct {
+	sync.Mutex
+	m map[testing.TB]*mediator.Registry
+}{m: map[testing.TB]*mediator.Registry{}}
+
+// registry returns the isolated registry for the specified test, creating it
+// if necessary.  A newly created registry is removed when the test and all
+// its subtests complete.
+func registry(t testing.TB) *mediator.Registry {
+	registries.Lock()
+	defer registries.Unlock()
+
+	if r, ok := registries.m[t]; ok {
+		return r
+	}
+
+	r := mediator.NewRegistry()
+	registries.m[t] = r
+	t.Cleanup(func() {
+		registries.Lock()
+		defer registries.Unlock()
+
+		delete(registries.m, t)
+	})
+	return r
+}
+
+// TestContext returns a background context bound to an isolated registry for
+// the specified test.  Mocks registered for the test using the test-scoped
+// mock factories (e.g. MockCommandT) are registered in this registry:
+//
+//	ctx := mediatortest.TestContext(t)
+//	mock := mediatortest.MockCommandResultT[getFoo.Request](t, &getFoo.Result{})
+//
+//	err := sut.DoSomething(ctx) // executes getFoo.Request using ctx
+//
+// When a request is executed using a context bound to a test registry, the
+// command registered in the test registry for the request type is called.
+// If there is no command registered for the request type in the test registry,
+// any command registered in the global registry is called.
+//
+// Each test (and subtest) has its own registry; mocks registered for one test
+// are not available to requests executed using the context of any other test.
+// Tests which mock the same request type can therefore be run in parallel.
+//
+// The registry and the mocks registered in it are removed when the test
+// completes.
+func TestContext(t testing.TB) context.Context {
+	return ContextWithTestRegistry(context.Background(), t)
+}
+
+// ContextWithTestRegistry returns a copy of the specified context bound to the
+// isolated registry for the specified test.  See TestContext.
+func ContextWithTestRegistry(ctx context.Context, t testing.TB) context.Context {
+	return mediator.ContextWithRegistry(ctx, registry(t))
+}
@@ -0,0 +1,151 @@
+package mediatortest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// faketb is a testing.TB which records errors reported by the code under
+// test rather than failing the test.
+type faketb struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (tb *faketb) Helper()           {}
+func (tb *faketb) Error(args ...any) { tb.errors = append(tb.errors, fmt.Sprint(args...)) }
+func (tb *faketb) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+func (tb *faketb) Fatalf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+func (tb *faketb) Cleanup(fn func()) { tb.cleanups = append(tb.cleanups, fn) }
+
+func TestTestContext(t *testing.T) {
+	t.Run("binds context to registry of test", func(t *testing.T) {
+		// ARRANGE
+		ctx := TestContext(t)
+		mock := MockCommandT[string, mediator.NoResultType](t)
+
+		// ACT
+		_, err := mediator.Execute(ctx, "request", mediator.NoResult)
+
+		// ASSERT
+		if err != nil || mock.WasNotCalled() {
+			t.Errorf("wanted mock called, got %v", err)
+		}
+	})
+
+	t.Run("mocks are not registered in global registry", func(t *testing.T) {
+		// ARRANGE
+		_ = MockCommandT[string, mediator.NoResultType](t)
+
+		// ACT
+		_, err := mediator.Execute(context.Background(), "request", mediator.NoResult)
+
+		// ASSERT
+		if !errors.As(err, new(*mediator.NoCommandForRequestTypeError)) {
+			t.Errorf("wanted NoCommandForRequestTypeError, got %v", err)
+		}
+	})
+
+	t.Run("falls back to global registry", func(t *testing.T) {
+		// ARRANGE
+		ctx := TestContext(t)
+		mock := mediator.MockCommandResult[string](42)
+		defer mock.Unregister()
+
+		// ACT
+		result, err := mediator.Execute(ctx, "request", new(int))
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wanted := 42
+		got := result
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("test mock is preferred over global registration", func(t *testing.T) {
+		// ARRANGE
+		ctx := TestContext(t)
+		global := mediator.MockCommandResult[string](1)
+		defer global.Unregister()
+		local := MockCommandResultT[string](t, 2)
+
+		// ACT
+		result, _ := mediator.Execute(ctx, "request", new(int))
+
+		// ASSERT
+		wanted := 2
+		got := result
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+		if global.WasCalled() || local.WasNotCalled() {
+			t.Error("wanted only test mock to be called")
+		}
+	})
+
+	t.Run("removes registry when test completes", func(t *testing.T) {
+		// ARRANGE
+		var ctx context.Context
+		var sub testing.TB
+		t.Run("subtest", func(t *testing.T) {
+			ctx = TestContext(t)
+			sub = t
+			_ = MockCommandT[string, mediator.NoResultType](t)
+		})
+
+		// ASSERT
+		registries.Lock()
+		_, ok := registries.m[sub]
+		registries.Unlock()
+		if ok {
+			t.Error("registry was not removed")
+		}
+
+		_, err := mediator.Execute(ctx, "request", mediator.NoResult)
+		if !errors.As(err, new(*mediator.NoCommandForRequestTypeError)) {
+			t.Errorf("wanted NoCommandForRequestTypeError, got %v", err)
+		}
+	})
+}
+
+func TestParallelTestMocks(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		i := i
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			t.Parallel()
+
+			// ARRANGE
+			ctx := TestContext(t)
+			mock := MockCommandResultT[string](t, i)
+
+			// ACT
+			result, err := mediator.Execute(ctx, "request", new(int))
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			wanted := i
+			got := result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+			if mock.NumRequests() != 1 {
+				t.Errorf("wanted 1 request, got %d", mock.NumRequests())
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package mediatortest
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// spySeq provides sequence numbers for calls observed by spies.  A single
+// sequence is shared by all spies so that the order of calls to different
+// commands may be determined.
+var spySeq uint64
+
+// SpyCall[TRequest, TResult] records a call to a command observed by a spy.
+//
+// A call is validated (if the command implements Validator) and then (if
+// validation succeeds) executed.
+type SpyCall[TRequest any, TResult any] struct {
+	Seq             uint64          // the sequence number of the call; sequence numbers increase across all spies
+	Context         context.Context // the context of the call
+	Request         TRequest        // the request
+	Validated       bool            // true if the request was validated by the command
+	ValidationError error           // the error returned by the Validator of the command, if any
+	Executed        bool            // true if the request was executed by the command
+	Result          TResult         // the result returned by the command
+	Error           error           // the error returned by the command
+}
+
+// SpyCommand[TRequest, TResult] wraps a registered command, recording the
+// calls to the command and the results returned (see Spy).
+type SpyCommand[TRequest any, TResult any] struct {
+	mu      sync.Mutex
+	cmd     mediator.CommandHandler[TRequest, TResult]
+	calls   []SpyCall[TRequest, TResult]
+	pending []int // indices of calls validated successfully and not yet executed
+}
+
+// validatingSpy[TRequest, TResult] wraps a registered command which also
+// implements Validator.
+type validatingSpy[TRequest any, TResult any] struct {
+	*SpyCommand[TRequest, TResult]
+	validator mediator.Validator[TRequest]
+}
+
+// Spy[TRequest, TResult] replaces the command registered for the request type
+// in the global registry with a spy which forwards each request to the command,
+// recording the calls to the command and the results returned.  The original
+// registration is restored when the test completes.
+//
+//	spy := mediatortest.Spy[getFoo.Request, *getFoo.Result](t)
+//
+//	err := sut.DoSomething(ctx)
+//
+//	if !spy.WasCalled() { ... }
+//
+// Requests are validated by the command only if it implements Validator; the
+// command also continues to determine whether requests are authorized and
+// executed in a unit of work.
+//
+// If no command is registered for the request type, or the registered command
+// does not return the specified result type, the test fails.
+//
+// Spy modifies the global registry (see mediator.WrapCommand) and so must not
+// be used in tests running in parallel with other tests that register commands
+// or execute requests using the global registry.
+func Spy[TRequest any, TResult any](t testing.TB) *SpyCommand[TRequest, TResult] {
+	t.Helper()
+
+	var sp *SpyCommand[TRequest, TResult]
+	restore, err := mediator.WrapCommand(func(cmd mediator.CommandHandler[TRequest, TResult]) mediator.CommandHandler[TRequest, TResult] {
+		sp = &SpyCommand[TRequest, TResult]{cmd: cmd}
+		if v, ok := cmd.(mediator.Validator[TRequest]); ok {
+			return validatingSpy[TRequest, TResult]{SpyCommand: sp, validator: v}
+		}
+		return sp
+	})
+	if err != nil {
+		t.Fatalf("unable to spy: %v", err)
+		return nil
+	}
+	t.Cleanup(restore)
+
+	return sp
+}
+
+// Validate satisfies the Validator interface, calling the Validator of the
+// command and recording the outcome.  If validation succeeds the call is
+// held as pending, to be matched with the subsequent execution of the same
+// request.
+func (sp validatingSpy[TRequest, TResult]) Validate(ctx context.Context, rq TRequest) error {
+	err := sp.validator.Validate(ctx, rq)
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	i := sp.record(SpyCall[TRequest, TResult]{Context: ctx, Request: rq, Validated: true, ValidationError: err})
+	if err == nil {
+		sp.pending = append(sp.pending, i)
+	}
+	return err
+}
+
+// Execute satisfies the CommandHandler interface, calling the command and
+// recording the result.  If the request was previously validated (with the
+// same context) the result is recorded in the pending call, otherwise a new
+// call is recorded.
+func (sp *SpyCommand[TRequest, TResult]) Execute(ctx context.Context, rq TRequest) (TResult, error) {
+	result, err := sp.cmd.Execute(ctx, rq)
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	for p, i := range sp.pending {
+		call := &sp.calls[i]
+		if call.Context == ctx && reflect.DeepEqual(call.Request, rq) {
+			call.Executed, call.Result, call.Error = true, result, err
+			sp.pending = append(sp.pending[:p], sp.pending[p+1:]...)
+			return result, err
+		}
+	}
+	sp.record(SpyCall[TRequest, TResult]{Context: ctx, Request: rq, Executed: true, Result: result, Error: err})
+	return result, err
+}
+
+// record records a new call to the command, returning the index of the call.
+// The mutex of the spy must be locked by the caller.
+func (sp *SpyCommand[TRequest, TResult]) record(call SpyCall[TRequest, TResult]) int {
+	call.Seq = atomic.AddUint64(&spySeq, 1)
+	sp.calls = append(sp.calls, call)
+	return len(sp.calls) - 1
+}
+
+// Calls returns a copy of the records of calls to the command, in the order in
+// which they were received.
+func (sp *SpyCommand[TRequest, TResult]) Calls() []SpyCall[TRequest, TResult] {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	return append([]SpyCall[TRequest, TResult]{}, sp.calls...)
+}
+
+// Requests returns a copy of the requests received by the command.
+func (sp *SpyCommand[TRequest, TResult]) Requests() []TRequest {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	result := make([]TRequest, 0, len(sp.calls))
+	for _, call := range sp.calls {
+		result = append(result, call.Request)
+	}
+	return result
+}
+
+// NumRequests returns the number of times the command was called.
+func (sp *SpyCommand[TRequest, TResult]) NumRequests() int {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	return len(sp.calls)
+}
+
+// WasCalled returns true if the command was called at least once.
+func (sp *SpyCommand[TRequest, TResult]) WasCalled() bool {
+	return sp.NumRequests() > 0
+}
+
+// WasNotCalled returns true if the command was not called.
+func (sp *SpyCommand[TRequest, TResult]) WasNotCalled() bool {
+	return sp.NumRequests() == 0
+}
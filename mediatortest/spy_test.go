@@ -0,0 +1,204 @@
+package mediatortest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/blugnu/mediator"
+)
+
+// spytestcmd is a command used for testing spies.
+type spytestcmd struct {
+	authorize bool
+}
+
+func (cmd *spytestcmd) Validate(_ context.Context, rq int) error {
+	if rq < 0 {
+		return errors.New("negative")
+	}
+	return nil
+}
+
+func (cmd *spytestcmd) Execute(_ context.Context, rq int) (int, error) {
+	return rq * 2, nil
+}
+
+// spytestauthcmd is a command implementing Authorizer used for testing spies.
+type spytestauthcmd struct {
+	spytestcmd
+}
+
+func (cmd *spytestauthcmd) Authorize(context.Context, any, int) error { return nil }
+
+// spytestnovalidatorcmd is a command which does not implement Validator, used
+// for testing spies.
+type spytestnovalidatorcmd struct{}
+
+func (spytestnovalidatorcmd) Execute(_ context.Context, rq string) (int, error) {
+	return len(rq), nil
+}
+
+func TestSpy(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	arrange := func(t *testing.T, cmd mediator.CommandHandler[int, int]) {
+		t.Helper()
+		t.Cleanup(mediator.RegisterMockCommand(ctx, cmd))
+	}
+
+	t.Run("forwards requests and records calls", func(t *testing.T) {
+		// ARRANGE
+		arrange(t, &spytestcmd{})
+		var sut *SpyCommand[int, int]
+
+		t.Run("spy", func(t *testing.T) {
+			sut = Spy[int, int](t)
+
+			// ACT
+			result, err := mediator.Execute(ctx, 2, new(int))
+			_, verr := mediator.Execute(ctx, -1, new(int))
+
+			// ASSERT
+			t.Run("returns result of command", func(t *testing.T) {
+				if result != 4 || err != nil {
+					t.Errorf("wanted 4, nil; got %d, %v", result, err)
+				}
+			})
+
+			t.Run("forwards validation", func(t *testing.T) {
+				if !errors.As(verr, new(mediator.ValidationError)) {
+					t.Errorf("wanted ValidationError, got %v", verr)
+				}
+			})
+
+			t.Run("records calls", func(t *testing.T) {
+				calls := sut.Calls()
+				wanted := []int{2, -1}
+				got := sut.Requests()
+				if !reflect.DeepEqual(wanted, got) {
+					t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+				}
+				if !calls[0].Validated || !calls[0].Executed || calls[0].Result != 4 || calls[1].Executed || calls[1].ValidationError == nil {
+					t.Errorf("unexpected calls: %#v", calls)
+				}
+			})
+		})
+
+		t.Run("restores registration", func(t *testing.T) {
+			// ACT
+			_, _ = mediator.Execute(ctx, 3, new(int))
+
+			// ASSERT
+			wanted := 2
+			got := sut.NumRequests()
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("when a spied mock is unregistered before the test completes", func(t *testing.T) {
+		// ARRANGE
+		t.Run("spy", func(t *testing.T) {
+			mock := mediator.MockCommandResult[int](1)
+			defer mock.Unregister()
+			Spy[int, int](t)
+		})
+
+		// ACT
+		mock := mediator.MockCommandResult[int](2)
+		defer mock.Unregister()
+		result, err := mediator.Execute(ctx, 1, new(int))
+
+		// ASSERT
+		wanted := 2
+		got := result
+		if err != nil || wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v (%v)", wanted, got, err)
+		}
+	})
+
+	t.Run("when the command does not implement Validator", func(t *testing.T) {
+		// ARRANGE
+		t.Cleanup(mediator.RegisterMockCommand[string, int](ctx, spytestnovalidatorcmd{}))
+		sut := Spy[string, int](t)
+
+		validated := false
+		remove := mediator.AddHooks(mediator.Hooks{AfterValidate: func(context.Context, mediator.ExecutionEvent) { validated = true }})
+		defer remove()
+
+		// ACT
+		result, err := mediator.Execute(ctx, "request", new(int))
+
+		// ASSERT
+		t.Run("returns result of command", func(t *testing.T) {
+			if result != 7 || err != nil {
+				t.Errorf("wanted 7, nil; got %d, %v", result, err)
+			}
+		})
+
+		t.Run("does not validate", func(t *testing.T) {
+			if validated {
+				t.Error("AfterValidate hook was called")
+			}
+		})
+
+		t.Run("records call", func(t *testing.T) {
+			calls := sut.Calls()
+			if len(calls) != 1 || calls[0].Validated || !calls[0].Executed || calls[0].Result != 7 {
+				t.Errorf("unexpected calls: %#v", calls)
+			}
+		})
+	})
+
+	t.Run("forwards optional interfaces of the command", func(t *testing.T) {
+		// ARRANGE
+		arrange(t, &spytestauthcmd{})
+		sut := Spy[int, int](t)
+
+		// ACT
+		_, err := mediator.Execute(ctx, 1, new(int))
+
+		// ASSERT
+		wanted := mediator.UnauthorizedError{E: mediator.ErrNoPrincipal}
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+		if sut.WasCalled() {
+			t.Error("wanted no calls")
+		}
+	})
+
+	t.Run("when no command is registered", func(t *testing.T) {
+		// ARRANGE
+		tb := &faketb{}
+
+		// ACT
+		Spy[int, int](tb)
+
+		// ASSERT
+		wanted := []string{"unable to spy: no command registered for requests of type: int"}
+		got := tb.errors
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when result type is different", func(t *testing.T) {
+		// ARRANGE
+		arrange(t, &spytestcmd{})
+		tb := &faketb{}
+
+		// ACT
+		Spy[int, string](tb)
+
+		// ASSERT
+		if len(tb.errors) != 1 {
+			t.Errorf("wanted 1 error, got %v", tb.errors)
+		}
+	})
+}
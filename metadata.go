@@ -0,0 +1,130 @@
+package mediator
+
+import (
+	"context"
+	"strings"
+)
+
+// Metadata is an envelope of information about a request, attached by the
+// mediator to the context passed to the command (and any hooks) for every
+// request executed.
+//
+// Each request is given a new ID.  Requests executed by a command (using the
+// context passed to the command) are nested requests: the CorrelationID is
+// propagated, identifying the operation of which the request is part, and the
+// CausationID identifies the request which caused it.  TenantID, Caller and
+// Extra are propagated unmodified.
+type Metadata struct {
+	ID            string            // identifies the request
+	CorrelationID string            // identifies the operation of which the request is part
+	CausationID   string            // identifies the request which caused the request (if any)
+	TenantID      string            // identifies the tenant for which the request is executed
+	Caller        string            // identifies the caller
+	Extra         map[string]string // any additional information
+}
+
+// keys used to represent Metadata in a map (see Metadata.Map).
+const (
+	MetadataID            = "mediator-id"
+	MetadataCorrelationID = "mediator-correlation-id"
+	MetadataCausationID   = "mediator-causation-id"
+	MetadataTenantID      = "mediator-tenant-id"
+	MetadataCaller        = "mediator-caller"
+	MetadataExtraPrefix   = "mediator-extra-"
+)
+
+// metadataKey is the context key for Metadata.
+type metadataKey struct{}
+
+// ContextWithMetadata returns a context with the specified metadata.  This is
+// used to establish the metadata for requests executed by the caller, e.g. by
+// a transport adapter, with metadata received with a message:
+//
+//	ctx = mediator.ContextWithMetadata(ctx, mediator.MetadataFromMap(msg.Headers))
+//
+// The metadata of a request executed using the context is derived from the
+// specified metadata, in the same way as a nested request: if the specified
+// metadata has an ID, this is the CausationID of the request.
+func ContextWithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// RequestMetadata returns the metadata associated with the specified context.
+// For the context passed to a command this is the metadata of the request
+// being executed.  If there is no metadata associated with the context, a
+// zero value is returned.
+func RequestMetadata(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
+
+// withRequestMetadata returns a context with the metadata for a new request,
+// derived from any metadata associated with the specified context.
+func withRequestMetadata(ctx context.Context) context.Context {
+	parent := RequestMetadata(ctx)
+
+	md := Metadata{
+		ID:            newID(),
+		CorrelationID: parent.CorrelationID,
+		CausationID:   parent.ID,
+		TenantID:      parent.TenantID,
+		Caller:        parent.Caller,
+	}
+	if parent.Extra != nil {
+		md.Extra = make(map[string]string, len(parent.Extra))
+		for k, v := range parent.Extra {
+			md.Extra[k] = v
+		}
+	}
+	switch {
+	case md.CorrelationID != "":
+	case parent.ID != "":
+		md.CorrelationID = parent.ID
+	default:
+		md.CorrelationID = md.ID
+	}
+
+	return ContextWithMetadata(ctx, md)
+}
+
+// Map returns a representation of the metadata as a map, e.g. for a transport
+// adapter to send as message headers.  Empty values are omitted.  Each Extra
+// value is represented with the key prefixed by MetadataExtraPrefix.
+func (md Metadata) Map() map[string]string {
+	m := map[string]string{}
+	set := func(k, v string) {
+		if v != "" {
+			m[k] = v
+		}
+	}
+	set(MetadataID, md.ID)
+	set(MetadataCorrelationID, md.CorrelationID)
+	set(MetadataCausationID, md.CausationID)
+	set(MetadataTenantID, md.TenantID)
+	set(MetadataCaller, md.Caller)
+	for k, v := range md.Extra {
+		set(MetadataExtraPrefix+k, v)
+	}
+	return m
+}
+
+// MetadataFromMap returns the metadata represented by a map, as returned by
+// Metadata.Map.  Keys not identifying metadata are ignored.
+func MetadataFromMap(m map[string]string) Metadata {
+	md := Metadata{
+		ID:            m[MetadataID],
+		CorrelationID: m[MetadataCorrelationID],
+		CausationID:   m[MetadataCausationID],
+		TenantID:      m[MetadataTenantID],
+		Caller:        m[MetadataCaller],
+	}
+	for k, v := range m {
+		if strings.HasPrefix(k, MetadataExtraPrefix) {
+			if md.Extra == nil {
+				md.Extra = map[string]string{}
+			}
+			md.Extra[strings.TrimPrefix(k, MetadataExtraPrefix)] = v
+		}
+	}
+	return md
+}
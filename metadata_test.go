@@ -0,0 +1,202 @@
+package mediator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// metadatatestouter and metadatatestinner are request types used for testing
+// the propagation of metadata to nested requests.
+type metadatatestouter struct{}
+type metadatatestinner struct{}
+
+func TestRequestMetadata(t *testing.T) {
+	// ARRANGE
+	arrange := func(t *testing.T) (context.Context, *Metadata, *Metadata) {
+		ctx := testContext(t)
+		outer, inner := &Metadata{}, &Metadata{}
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ metadatatestinner) (NoResultType, error) {
+			*inner = RequestMetadata(ctx)
+			return nil, nil
+		})
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ metadatatestouter) (NoResultType, error) {
+			*outer = RequestMetadata(ctx)
+			return Execute(ctx, metadatatestinner{}, NoResult)
+		})
+		return ctx, outer, inner
+	}
+
+	t.Run("with no metadata", func(t *testing.T) {
+		// ARRANGE
+		ctx, outer, inner := arrange(t)
+
+		// ACT
+		_, err := Execute(ctx, metadatatestouter{}, NoResult)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		t.Run("request has an id", func(t *testing.T) {
+			wanted := true
+			got := outer.ID != "" && inner.ID != "" && outer.ID != inner.ID
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("correlation id", func(t *testing.T) {
+			wanted := []string{outer.ID, outer.ID}
+			got := []string{outer.CorrelationID, inner.CorrelationID}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("causation id", func(t *testing.T) {
+			wanted := []string{"", outer.ID}
+			got := []string{outer.CausationID, inner.CausationID}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("with metadata from a transport", func(t *testing.T) {
+		// ARRANGE
+		ctx, outer, inner := arrange(t)
+		ctx = ContextWithMetadata(ctx, Metadata{
+			ID:            "message",
+			CorrelationID: "correlation",
+			TenantID:      "tenant",
+			Caller:        "caller",
+			Extra:         map[string]string{"key": "value"},
+		})
+
+		// ACT
+		_, err := Execute(ctx, metadatatestouter{}, NoResult)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		wanted := []Metadata{
+			{ID: outer.ID, CorrelationID: "correlation", CausationID: "message", TenantID: "tenant", Caller: "caller", Extra: map[string]string{"key": "value"}},
+			{ID: inner.ID, CorrelationID: "correlation", CausationID: outer.ID, TenantID: "tenant", Caller: "caller", Extra: map[string]string{"key": "value"}},
+		}
+		got := []Metadata{*outer, *inner}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("with no correlation id", func(t *testing.T) {
+		// ARRANGE
+		ctx, outer, _ := arrange(t)
+		ctx = ContextWithMetadata(ctx, Metadata{ID: "message"})
+
+		// ACT
+		_, _ = Execute(ctx, metadatatestouter{}, NoResult)
+
+		// ASSERT
+		wanted := "message"
+		got := outer.CorrelationID
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("available to hooks", func(t *testing.T) {
+		// ARRANGE
+		ctx, outer, _ := arrange(t)
+		var md Metadata
+		remove := AddHooks(Hooks{BeforeExecute: func(ctx context.Context, e ExecutionEvent) {
+			if e.RequestType == reflect.TypeOf(metadatatestouter{}) {
+				md = RequestMetadata(ctx)
+			}
+		}})
+		defer remove()
+
+		// ACT
+		_, _ = Execute(ctx, metadatatestouter{}, NoResult)
+
+		// ASSERT
+		wanted := *outer
+		got := md
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("with no context metadata", func(t *testing.T) {
+		// ACT
+		result := RequestMetadata(context.Background())
+
+		// ASSERT
+		wanted := Metadata{}
+		got := result
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestMetadataMap(t *testing.T) {
+	// ARRANGE
+	md := Metadata{
+		ID:            "id",
+		CorrelationID: "correlation",
+		CausationID:   "causation",
+		TenantID:      "tenant",
+		Caller:        "caller",
+		Extra:         map[string]string{"key": "value"},
+	}
+
+	t.Run("Map", func(t *testing.T) {
+		// ACT
+		result := md.Map()
+
+		// ASSERT
+		wanted := map[string]string{
+			"mediator-id":             "id",
+			"mediator-correlation-id": "correlation",
+			"mediator-causation-id":   "causation",
+			"mediator-tenant-id":      "tenant",
+			"mediator-caller":         "caller",
+			"mediator-extra-key":      "value",
+		}
+		got := result
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("omits empty values", func(t *testing.T) {
+		// ACT
+		result := Metadata{ID: "id"}.Map()
+
+		// ASSERT
+		wanted := map[string]string{"mediator-id": "id"}
+		got := result
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("MetadataFromMap", func(t *testing.T) {
+		// ARRANGE
+		m := md.Map()
+		m["content-type"] = "application/json"
+
+		// ACT
+		result := MetadataFromMap(m)
+
+		// ASSERT
+		wanted := md
+		got := result
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
@@ -3,6 +3,10 @@ package mediator
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // executeFunc[TRequest, TResult] is the signature of a function that implements
@@ -13,59 +17,223 @@ type executeFunc[TRequest any, TResult any] func(context.Context, TRequest) (TRe
 // the Validator[TRequest] interface.
 type validateFunc[TRequest any] func(context.Context, TRequest) error
 
-// mockCommand[TRequest any, TResult any] is a mock command that can be
+// mockSeq provides sequence numbers for calls to mock commands.  A single
+// sequence is shared by all mocks so that the order of calls to different
+// mocks may be determined.
+var mockSeq uint64
+
+// MockCall[TRequest] records a call to a mock command.
+//
+// A call is normally validated and then (if validation succeeds) executed.  A
+// call which did not pass through validation (e.g. when the mock is called
+// directly) is recorded as executed but not validated.
+type MockCall[TRequest any] struct {
+	Seq             uint64          // the sequence number of the call; sequence numbers increase across all mocks
+	Context         context.Context // the context of the call
+	Request         TRequest        // the request
+	Validated       bool            // true if the request was validated by the mock
+	ValidationError error           // the error returned by the validation function of the mock, if any
+	Executed        bool            // true if the request was executed by the mock
+}
+
+// Mock[TRequest, TResult] is a mock command that can be
 // used in tests to verify that a command is called with the expected parameters
 // and/or to return a specified result or error.
-type mockcommand[TRequest any, TResult any] struct {
-	requests   []TRequest
-	validate   validateFunc[TRequest]
-	execute    executeFunc[TRequest, TResult]
-	unregister func()
+//
+// A mock command is safe for concurrent use.
+type Mock[TRequest any, TResult any] struct {
+	mu           sync.Mutex
+	requests     []TRequest
+	calls        []MockCall[TRequest]
+	pending      []int // indices of calls validated successfully and not yet executed
+	called       chan struct{}
+	validate     validateFunc[TRequest]
+	execute      executeFunc[TRequest, TResult]
+	expectations []*Expectation[TRequest, TResult]
+	unexpected   []TRequest
+	unregister   func()
+}
+
+// record records a new call to the mock, notifying any goroutines waiting for
+// calls.  The mutex of the mock must be locked by the caller.
+func (mock *Mock[TRequest, TResult]) record(call MockCall[TRequest]) int {
+	call.Seq = atomic.AddUint64(&mockSeq, 1)
+	mock.requests = append(mock.requests, call.Request)
+	mock.calls = append(mock.calls, call)
+
+	if mock.called != nil {
+		close(mock.called)
+		mock.called = nil
+	}
+	return len(mock.calls) - 1
+}
+
+// recordValidated records a call validated by the mock.  If validation was
+// successful the call is held as pending, to be matched with the subsequent
+// execution of the same request.
+func (mock *Mock[TRequest, TResult]) recordValidated(ctx context.Context, rq TRequest, err error) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	i := mock.record(MockCall[TRequest]{Context: ctx, Request: rq, Validated: true, ValidationError: err})
+	if err == nil {
+		mock.pending = append(mock.pending, i)
+	}
+}
+
+// recordExecuted records the execution of a request by the mock.  If the
+// request was previously validated (with the same context) the pending call is
+// marked as executed, otherwise a new call is recorded which was executed
+// without validation.
+func (mock *Mock[TRequest, TResult]) recordExecuted(ctx context.Context, rq TRequest) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	for p, i := range mock.pending {
+		call := &mock.calls[i]
+		if call.Context == ctx && reflect.DeepEqual(call.Request, rq) {
+			call.Executed = true
+			mock.pending = append(mock.pending[:p], mock.pending[p+1:]...)
+			return
+		}
+	}
+	mock.record(MockCall[TRequest]{Context: ctx, Request: rq, Executed: true})
 }
 
 // Validate satisfies the Validator interface
-func (mock *mockcommand[TRequest, TResult]) Validate(ctx context.Context, rq TRequest) error {
-	mock.requests = append(mock.requests, rq)
+func (mock *Mock[TRequest, TResult]) Validate(ctx context.Context, rq TRequest) error {
+	var err error
 	if mock.validate != nil {
-		return mock.validate(ctx, rq)
+		err = mock.validate(ctx, rq)
 	}
-	return nil
+	mock.recordValidated(ctx, rq, err)
+	return err
 }
 
-// Execute satisfies the CommandExecutor interface
-func (mock *mockcommand[TRequest, TResult]) Execute(ctx context.Context, rq TRequest) (TResult, error) {
-	return mock.execute(ctx, rq)
+// Execute satisfies the CommandExecutor interface.  If any expectations have
+// been established on the mock, the response is determined by those
+// expectations.
+func (mock *Mock[TRequest, TResult]) Execute(ctx context.Context, rq TRequest) (TResult, error) {
+	mock.recordExecuted(ctx, rq)
+
+	mock.mu.Lock()
+	hasExpectations := len(mock.expectations) > 0
+	mock.mu.Unlock()
+
+	switch {
+	case hasExpectations:
+		return mock.expected(ctx, rq)
+	case mock.execute != nil:
+		return mock.execute(ctx, rq)
+	}
+	return *new(TResult), nil
 }
 
-// NumRequests returns the number of times the mock was called.
-func (mock *mockcommand[TRequest, TResult]) NumRequests() int {
+// NumRequests returns the number of times the mock was called, including calls
+// rejected by validation and calls executed without validation.
+func (mock *Mock[TRequest, TResult]) NumRequests() int {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
 	return len(mock.requests)
 }
 
 // Requests returns a copy of the slice of requests received by the mock.
-func (mock *mockcommand[TRequest, TResult]) Requests() []TRequest {
+func (mock *Mock[TRequest, TResult]) Requests() []TRequest {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
 	return append([]TRequest{}, mock.requests...)
 }
 
+// Calls returns a copy of the records of calls received by the mock, in the
+// order in which they were received.
+func (mock *Mock[TRequest, TResult]) Calls() []MockCall[TRequest] {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	return append([]MockCall[TRequest]{}, mock.calls...)
+}
+
+// ValidatedCalls returns a copy of the records of calls which were successfully
+// validated by the mock.
+func (mock *Mock[TRequest, TResult]) ValidatedCalls() []MockCall[TRequest] {
+	return mock.filterCalls(func(call MockCall[TRequest]) bool { return call.Validated && call.ValidationError == nil })
+}
+
+// RejectedCalls returns a copy of the records of calls for which the validation
+// function of the mock returned an error.  Rejected calls are not executed.
+func (mock *Mock[TRequest, TResult]) RejectedCalls() []MockCall[TRequest] {
+	return mock.filterCalls(func(call MockCall[TRequest]) bool { return call.ValidationError != nil })
+}
+
+// ExecutedCalls returns a copy of the records of calls executed by the mock,
+// whether or not the call was validated.
+func (mock *Mock[TRequest, TResult]) ExecutedCalls() []MockCall[TRequest] {
+	return mock.filterCalls(func(call MockCall[TRequest]) bool { return call.Executed })
+}
+
+// filterCalls returns a copy of the records of calls satisfying a predicate.
+func (mock *Mock[TRequest, TResult]) filterCalls(fn func(MockCall[TRequest]) bool) []MockCall[TRequest] {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	result := []MockCall[TRequest]{}
+	for _, call := range mock.calls {
+		if fn(call) {
+			result = append(result, call)
+		}
+	}
+	return result
+}
+
 // WasCalled returns true if the mock was called at least once.
-func (mock *mockcommand[TRequest, TResult]) WasCalled() bool {
-	return len(mock.requests) > 0
+func (mock *Mock[TRequest, TResult]) WasCalled() bool {
+	return mock.NumRequests() > 0
 }
 
 // WasNotCalled returns true if the mock was not called.
-func (mock *mockcommand[TRequest, TResult]) WasNotCalled() bool {
-	return len(mock.requests) == 0
+func (mock *Mock[TRequest, TResult]) WasNotCalled() bool {
+	return mock.NumRequests() == 0
+}
+
+// WaitForCalls waits until the mock has been called at least n times or the
+// specified timeout expires, returning an error if the timeout expires.  This
+// is useful when the mock is called by code running in other goroutines.
+func (mock *Mock[TRequest, TResult]) WaitForCalls(n int, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		mock.mu.Lock()
+		got := len(mock.calls)
+		if got >= n {
+			mock.mu.Unlock()
+			return nil
+		}
+		if mock.called == nil {
+			mock.called = make(chan struct{})
+		}
+		called := mock.called
+		mock.mu.Unlock()
+
+		select {
+		case <-called:
+		case <-timer.C:
+			return fmt.Errorf("timed out after %v waiting for %d call(s) to %T: got %d", timeout, n, mock, got)
+		}
+	}
 }
 
-func (mock *mockcommand[TRequest, TResult]) Unregister() {
+func (mock *Mock[TRequest, TResult]) Unregister() {
 	mock.unregister()
 }
 
 // registerMockCommand registers a mock command for the specified request and result
 // type using the specified command functions for validating requests and executing
 // the command.
-func registerMockCommand[TRequest any, TResult any](val validateFunc[TRequest], cmd executeFunc[TRequest, TResult]) *mockcommand[TRequest, TResult] {
-	mock := &mockcommand[TRequest, TResult]{
+func registerMockCommand[TRequest any, TResult any](val validateFunc[TRequest], cmd executeFunc[TRequest, TResult]) *Mock[TRequest, TResult] {
+	mock := &Mock[TRequest, TResult]{
 		validate: val,
 		execute:  cmd,
 	}
@@ -76,21 +244,21 @@ func registerMockCommand[TRequest any, TResult any](val validateFunc[TRequest],
 // MockCommand registers a mock command for the specified request and result type
 // modelling successful execution of the command returning a zero-value result
 // and no error.
-func MockCommand[TRequest any, TResult any]() *mockcommand[TRequest, TResult] {
+func MockCommand[TRequest any, TResult any]() *Mock[TRequest, TResult] {
 	return MockCommandResult[TRequest](*new(TResult))
 }
 
 // MockCommandError registers a mock command for the specified request and result
 // type modelling a failed execution of the command, returning the specified
 // error and a zero-value result.
-func MockCommandError[TRequest any, TResult any](err error) *mockcommand[TRequest, TResult] {
+func MockCommandError[TRequest any, TResult any](err error) *Mock[TRequest, TResult] {
 	return registerMockCommand(nil, func(ctx context.Context, rq TRequest) (TResult, error) { return *new(TResult), err })
 }
 
 // MockCommandResult registers a mock command for the specified request and result
 // type modelling successful execution of the command returning the specified
 // result and a nil error.
-func MockCommandResult[TRequest any, TResult any](result TResult) *mockcommand[TRequest, TResult] {
+func MockCommandResult[TRequest any, TResult any](result TResult) *Mock[TRequest, TResult] {
 	return registerMockCommand(nil, func(ctx context.Context, rq TRequest) (TResult, error) { return result, nil })
 }
 
@@ -99,10 +267,39 @@ func MockCommandResult[TRequest any, TResult any](result TResult) *mockcommand[T
 //
 // The specified error will be returned by the validator of the mocked command (and
 // will therefore be wrapped in a ValidationError).
-func MockCommandValidationError[TRequest any, TResult any](err error) *mockcommand[TRequest, TResult] {
+func MockCommandValidationError[TRequest any, TResult any](err error) *Mock[TRequest, TResult] {
 	return registerMockCommand[TRequest, TResult](func(context.Context, TRequest) error { return err }, nil)
 }
 
+// MockCommandFunc registers a mock command for the specified request and result
+// type which validates requests using the specified validation function and
+// executes them using the specified execution function.  Either function may
+// be nil; a nil validation function accepts all requests and a nil execution
+// function returns a zero-value result and nil error.
+func MockCommandFunc[TRequest any, TResult any](validate func(context.Context, TRequest) error, execute func(context.Context, TRequest) (TResult, error)) *Mock[TRequest, TResult] {
+	return registerMockCommand[TRequest, TResult](validate, execute)
+}
+
+// MockCommandFuncIn registers a mock command for the specified request and
+// result type in the specified registry, which validates requests using the
+// specified validation function and executes them using the specified
+// execution function (see MockCommandFunc).
+//
+// If a command is already registered for the request type in the registry, a
+// CommandAlreadyRegisteredError is returned.
+func MockCommandFuncIn[TRequest any, TResult any](r *Registry, validate func(context.Context, TRequest) error, execute func(context.Context, TRequest) (TResult, error)) (*Mock[TRequest, TResult], error) {
+	mock := &Mock[TRequest, TResult]{
+		validate: validate,
+		execute:  execute,
+	}
+	unreg, err := r.register(context.Background(), *new(TRequest), mock)
+	if err != nil {
+		return nil, err
+	}
+	mock.unregister = unreg
+	return mock, nil
+}
+
 // RegisterMockCommand registers a custom mock command, returning a function to unregister
 // the mock when no longer required:
 //
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestMockCommand(t *testing.T) {
@@ -238,3 +240,190 @@ func TestRegisterMock(t *testing.T) {
 		RegisterMockCommand[int, NoResultType](ctx, cmd)
 	})
 }
+
+// mocktestkey is a context key used for testing the recording of contexts.
+type mocktestkey struct{}
+
+func TestMockCommandCalls(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	t.Run("records concurrent calls", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[int, NoResultType]()
+		defer mock.Unregister()
+
+		// ACT
+		wg := sync.WaitGroup{}
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, _ = Execute(ctx, i, NoResult)
+			}(i)
+		}
+		wg.Wait()
+
+		// ASSERT
+		wanted := 100
+		got := mock.NumRequests()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("records calls in order with context", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[int, NoResultType]()
+		defer mock.Unregister()
+		ctx1 := context.WithValue(ctx, mocktestkey{}, "first")
+		ctx2 := context.WithValue(ctx, mocktestkey{}, "second")
+
+		// ACT
+		_, _ = Execute(ctx1, 1, NoResult)
+		_, _ = Execute(ctx2, 2, NoResult)
+
+		// ASSERT
+		calls := mock.Calls()
+		if len(calls) != 2 {
+			t.Fatalf("wanted 2 calls, got %d", len(calls))
+		}
+
+		t.Run("sequence", func(t *testing.T) {
+			wanted := true
+			got := calls[0].Seq < calls[1].Seq
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("requests", func(t *testing.T) {
+			wanted := []int{1, 2}
+			got := []int{calls[0].Request, calls[1].Request}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("contexts", func(t *testing.T) {
+			wanted := []any{"first", "second"}
+			got := []any{calls[0].Context.Value(mocktestkey{}), calls[1].Context.Value(mocktestkey{})}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+}
+
+func TestMockCommandWaitForCalls(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	t.Run("when calls are received", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[int, NoResultType]()
+		defer mock.Unregister()
+
+		// ACT
+		go func() {
+			for i := 0; i < 3; i++ {
+				time.Sleep(time.Millisecond)
+				_, _ = Execute(ctx, i, NoResult)
+			}
+		}()
+		err := mock.WaitForCalls(3, time.Second)
+
+		// ASSERT
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("when calls are not received", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[int, NoResultType]()
+		defer mock.Unregister()
+		_, _ = Execute(ctx, 1, NoResult)
+
+		// ACT
+		err := mock.WaitForCalls(2, 10*time.Millisecond)
+
+		// ASSERT
+		if err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestMockCommandCallOutcomes(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	experr := errors.New("invalid")
+	mock := MockCommandFunc(
+		func(_ context.Context, rq int) error {
+			if rq < 0 {
+				return experr
+			}
+			return nil
+		},
+		func(_ context.Context, rq int) (string, error) { return "ok", nil },
+	)
+	defer mock.Unregister()
+
+	// ACT
+	r1, err1 := Execute(ctx, 1, new(string))
+	_, err2 := Execute(ctx, -1, new(string))
+	r3, err3 := mock.Execute(ctx, 3)
+
+	// ASSERT
+	t.Run("results", func(t *testing.T) {
+		if r1 != "ok" || err1 != nil || r3 != "ok" || err3 != nil {
+			t.Errorf("wanted ok results, got %q, %v and %q, %v", r1, err1, r3, err3)
+		}
+		if !errors.Is(err2, ValidationError{E: experr}) {
+			t.Errorf("wanted validation error, got %v", err2)
+		}
+	})
+
+	t.Run("counts all calls", func(t *testing.T) {
+		wanted := 3
+		got := mock.NumRequests()
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	requests := func(calls []MockCall[int]) []int {
+		result := []int{}
+		for _, call := range calls {
+			result = append(result, call.Request)
+		}
+		return result
+	}
+
+	testcases := []struct {
+		name   string
+		calls  []MockCall[int]
+		result []int
+	}{
+		{name: "validated", calls: mock.ValidatedCalls(), result: []int{1}},
+		{name: "rejected", calls: mock.RejectedCalls(), result: []int{-1}},
+		{name: "executed", calls: mock.ExecutedCalls(), result: []int{1, 3}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			wanted := tc.result
+			got := requests(tc.calls)
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+
+	t.Run("identifies call executed without validation", func(t *testing.T) {
+		call := mock.Calls()[2]
+		if call.Validated || !call.Executed {
+			t.Errorf("wanted executed without validation, got %#v", call)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// ErrNoName is returned when registering a named command if no name is
+// specified.
+var ErrNoName = errors.New("no name")
+
+// namedCommands holds the commands registered with a name, keyed by request
+// type and name.
+var namedCommands keyedRegistry
+
+// commandSelectors holds the command selectors established for request types.
+var commandSelectors = struct {
+	sync.RWMutex
+	selectors map[reflect.Type]any
+}{selectors: map[reflect.Type]any{}}
+
+// RegisterNamedCommand[TRequest, TResult] registers a command returning a
+// specific result type for the specified request type, with a name.  Any
+// number of commands may be registered for a request type with different
+// names (e.g. "stripe" and "paypal" implementations of a ChargeCard request).
+//
+// A named command executes requests executed using ExecuteNamed with the
+// name, or using Execute if a command selector is established for the request
+// type (see SetCommandSelector) which selects the name.
+//
+// Named commands are independent of any command registered using
+// RegisterCommand.  Named commands for the same request type should return the
+// same result type, since callers are typically unaware of which command is
+// executed.
+//
+// If the name is empty the function will return ErrNoName; requests executed
+// with an empty name are executed by the command registered using
+// RegisterCommand.
+//
+// If a command is already registered for the request type with the name the
+// function will return a CommandAlreadyRegisteredError.
+//
+// If the command being registered implements the ConfigurationChecker
+// interface, this is called and any error returned.
+//
+// Any OnRegister hooks established using AddHooks are called with the outcome.
+func RegisterNamedCommand[TRequest any, TResult any](ctx context.Context, name string, cmd CommandHandler[TRequest, TResult]) error {
+	_, err := registerNamed(ctx, name, *new(TRequest), cmd)
+	fireHook(ctx, onRegister, RegistrationEvent{RequestType: reflect.TypeOf(*new(TRequest)), Name: name, Handler: cmd, Err: err})
+	return err
+}
+
+// registerNamed registers a named command, returning a function which removes
+// the registration.  The registration is subject to the same checks as
+// registrations in the global registry.
+func registerNamed(ctx context.Context, name string, rq any, cmd any) (func(), error) {
+	if name == "" {
+		return nil, ErrNoName
+	}
+	return namedCommands.register(ctx, commandKey{rqt: reflect.TypeOf(rq), name: name}, rq, cmd)
+}
+
+// SetCommandSelector[TRequest] establishes a function which selects the name
+// of the command executing each request of the specified type executed using
+// Execute, from the request and/or context:
+//
+//	mediator.SetCommandSelector(func(ctx context.Context, rq ChargeCard.Request) string {
+//	    return rq.Provider
+//	})
+//
+// If the selector returns an empty string, the request is executed by the
+// command registered using RegisterCommand.  If no command is registered with
+// the name selected, a NoCommandForKeyError is returned.
+//
+// Requests executed using ExecuteNamed are not subject to the selector.
+// Specifying a nil function removes any selector for the request type.
+func SetCommandSelector[TRequest any](fn func(context.Context, TRequest) string) {
+	rqt := reflect.TypeOf(*new(TRequest))
+
+	commandSelectors.Lock()
+	defer commandSelectors.Unlock()
+
+	if fn == nil {
+		delete(commandSelectors.selectors, rqt)
+		return
+	}
+	commandSelectors.selectors[rqt] = fn
+}
+
+// ExecuteNamed executes the specified request using the command registered
+// for the request type with the specified name (see RegisterNamedCommand),
+// returning the result.  If the name is empty, the request is executed by the
+// command registered using RegisterCommand.
+//
+// If no command is registered for the request type with the name, a
+// NoCommandForKeyError is returned.  Otherwise, the request is executed in the
+// same way as Execute.
+func ExecuteNamed[TRequest any, TResult any](ctx context.Context, name string, req TRequest, resultHint *TResult) (TResult, error) {
+	return execute[TRequest, TResult](ctx, req, &name)
+}
+
+// resolve returns the command registered to execute the specified request,
+// with the specified name.  If no name is specified, the name is identified by
+// any selector established for the request type.  If there is no name (or the
+// name is empty), the command registered for the request type is returned or,
+// if there is none, any command registered for the type to which the request
+// type points (with pointer equivalence) or for an interface implemented by the
+// request type, adapted to the request and result type.
+func resolve[TRequest any, TResult any](ctx context.Context, req TRequest, name *string) (any, error) {
+	rqt := reflect.TypeOf(req)
+
+	if name == nil {
+		commandSelectors.RLock()
+		sel, ok := commandSelectors.selectors[rqt].(func(context.Context, TRequest) string)
+		commandSelectors.RUnlock()
+		if ok {
+			selected := sel(ctx, req)
+			name = &selected
+		}
+	}
+
+	if name != nil && *name != "" {
+		cmd, ok := namedCommands.lookup(commandKey{rqt: rqt, name: *name})
+		if !ok {
+			return nil, &NoCommandForKeyError{request: req, key: *name}
+		}
+		return cmd, nil
+	}
+
+	if cmd, ok := lookup(ctx, rqt); ok {
+		return cmd, nil
+	}
+	if cmd, ok := lookupEquivalent(ctx, rqt); ok {
+		return adapt[TRequest, TResult](cmd, rqt.Elem(), true), nil
+	}
+
+	reg, ok, err := lookupInterface(rqt)
+	switch {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return nil, &NoCommandForRequestTypeError{request: req, nearMisses: nearMisses(ctx, rqt)}
+	}
+	return adapt[TRequest, TResult](reg.cmd, reg.iface, false), nil
+}
@@ -0,0 +1,206 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// namedtestrequest is a request type used for testing named registrations.
+type namedtestrequest struct{ provider string }
+
+// namedtestcmd is a command returning a specific result.
+type namedtestcmd string
+
+func (cmd namedtestcmd) Execute(context.Context, namedtestrequest) (string, error) {
+	return string(cmd), nil
+}
+
+// resetNamedCommands replaces the named commands and selectors for the
+// duration of a test.
+func resetNamedCommands(t *testing.T) {
+	ogc, ogs := namedCommands.commands, commandSelectors.selectors
+	namedCommands.commands = nil
+	commandSelectors.selectors = map[reflect.Type]any{}
+	t.Cleanup(func() { namedCommands.commands, commandSelectors.selectors = ogc, ogs })
+}
+
+func TestNamedCommands(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	rqt := reflect.TypeOf(namedtestrequest{})
+
+	arrange := func(t *testing.T) {
+		resetNamedCommands(t)
+		if err := RegisterCommand[namedtestrequest, string](ctx, namedtestcmd("default")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		t.Cleanup(func() { delete(commands, rqt) })
+		for _, name := range []string{"stripe", "paypal"} {
+			if err := RegisterNamedCommand[namedtestrequest, string](ctx, name, namedtestcmd(name)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+
+	t.Run("ExecuteNamed", func(t *testing.T) {
+		// ARRANGE
+		arrange(t)
+
+		testcases := []struct {
+			name   string
+			result string
+			err    error
+		}{
+			{name: "stripe", result: "stripe"},
+			{name: "paypal", result: "paypal"},
+			{name: "", result: "default"},
+			{name: "other", err: NoCommandForKeyError{request: namedtestrequest{}, key: "other"}},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				// ACT
+				result, err := ExecuteNamed(ctx, tc.name, namedtestrequest{}, new(string))
+
+				// ASSERT
+				wanted := []any{tc.result, true}
+				got := []any{result, errors.Is(err, tc.err)}
+				if !reflect.DeepEqual(wanted, got) {
+					t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+				}
+			})
+		}
+	})
+
+	t.Run("selector", func(t *testing.T) {
+		// ARRANGE
+		arrange(t)
+		SetCommandSelector(func(_ context.Context, rq namedtestrequest) string { return rq.provider })
+
+		testcases := []struct {
+			provider string
+			result   string
+			err      error
+		}{
+			{provider: "stripe", result: "stripe"},
+			{provider: "", result: "default"},
+			{provider: "other", err: NoCommandForKeyError{request: namedtestrequest{}, key: "other"}},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.provider, func(t *testing.T) {
+				// ACT
+				result, err := Execute(ctx, namedtestrequest{provider: tc.provider}, new(string))
+
+				// ASSERT
+				wanted := []any{tc.result, true}
+				got := []any{result, errors.Is(err, tc.err)}
+				if !reflect.DeepEqual(wanted, got) {
+					t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+				}
+			})
+		}
+
+		t.Run("not applied to ExecuteNamed", func(t *testing.T) {
+			// ACT
+			result, _ := ExecuteNamed(ctx, "paypal", namedtestrequest{provider: "stripe"}, new(string))
+
+			// ASSERT
+			wanted := "paypal"
+			got := result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("when removed", func(t *testing.T) {
+			// ARRANGE
+			SetCommandSelector[namedtestrequest](nil)
+
+			// ACT
+			result, _ := Execute(ctx, namedtestrequest{provider: "stripe"}, new(string))
+
+			// ASSERT
+			wanted := "default"
+			got := result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("when no name is specified", func(t *testing.T) {
+		// ARRANGE
+		resetNamedCommands(t)
+
+		// ACT
+		err := RegisterNamedCommand[namedtestrequest, string](ctx, "", namedtestcmd("none"))
+
+		// ASSERT
+		t.Run("returns error", func(t *testing.T) {
+			wanted := ErrNoName
+			got := err
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("does not register the command", func(t *testing.T) {
+			if !namedCommands.empty() {
+				t.Error("command registered")
+			}
+		})
+	})
+
+	t.Run("when already registered with name", func(t *testing.T) {
+		// ARRANGE
+		arrange(t)
+
+		// ACT
+		err := RegisterNamedCommand[namedtestrequest, string](ctx, "stripe", namedtestcmd("other"))
+
+		// ASSERT
+		wanted := CommandAlreadyRegisteredError{request: namedtestrequest{}}
+		got := err
+		if !errors.Is(got, wanted) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when the ConfigurationChecker executes a named request", func(t *testing.T) {
+		// ARRANGE
+		arrange(t)
+		cmd := registrationexecutingtestcmd{exec: func(ctx context.Context) { _, _ = ExecuteNamed(ctx, "stripe", namedtestrequest{}, new(string)) }}
+
+		// ACT
+		ok := completes(func() { _ = RegisterNamedCommand[int, NoResultType](ctx, "stripe", cmd) })
+
+		// ASSERT
+		if !ok {
+			t.Error("registration did not complete")
+		}
+	})
+
+	t.Run("registrations", func(t *testing.T) {
+		// ARRANGE
+		arrange(t)
+
+		// ACT
+		result := Registrations()
+
+		// ASSERT
+		info := func(name string) RegistrationInfo {
+			return RegistrationInfo{
+				RequestType: "github.com/blugnu/mediator.namedtestrequest",
+				Name:        name,
+				ResultType:  "string",
+				HandlerType: "github.com/blugnu/mediator.namedtestcmd",
+			}
+		}
+		wanted := []RegistrationInfo{info(""), info("paypal"), info("stripe")}
+		got := result
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package mediator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxExecutionDepth is the default maximum depth of nested requests.
+const DefaultMaxExecutionDepth = 32
+
+var maxExecutionDepth = DefaultMaxExecutionDepth
+
+// SetMaxExecutionDepth establishes the maximum depth of nested requests, i.e.
+// requests executed by commands executing other requests.  A request that
+// would exceed the maximum depth is not executed; an ExecutionDepthError is
+// returned.  Specifying a depth of zero (or less) removes the limit.
+func SetMaxExecutionDepth(depth int) {
+	maxExecutionDepth = depth
+}
+
+// executionFrame identifies a request type being executed, and the frame of
+// the request being executed by the command which executed it (if any).
+type executionFrame struct {
+	rqt    reflect.Type
+	parent *executionFrame
+	depth  int
+	call   *Call
+}
+
+// executionFrameKey is the context key for the current executionFrame.
+type executionFrameKey struct{}
+
+// chain returns the request types in the chain of frames, outermost first.
+func (f *executionFrame) chain() []reflect.Type {
+	if f == nil {
+		return nil
+	}
+	return append(f.parent.chain(), f.rqt)
+}
+
+// ExecutionChain returns the request types being executed in the chain of
+// nested requests leading to the specified context, outermost first.  For
+// the context passed to a command, the last request type in the chain is the
+// request type being executed by the command.
+func ExecutionChain(ctx context.Context) []reflect.Type {
+	f, _ := ctx.Value(executionFrameKey{}).(*executionFrame)
+	return f.chain()
+}
+
+// enter establishes a frame for the execution of a request of the specified
+// type, returning a context with the frame and a function to be called with
+// the outcome when execution is complete.  If the request type is already
+// being executed in the chain of nested requests a CommandCycleError is
+// returned; if the maximum depth would be exceeded an ExecutionDepthError is
+// returned.
+func enter(ctx context.Context, rqt reflect.Type) (context.Context, func(error), error) {
+	parent, _ := ctx.Value(executionFrameKey{}).(*executionFrame)
+
+	frame := &executionFrame{rqt: rqt, parent: parent, depth: 1}
+	if parent != nil {
+		frame.depth = parent.depth + 1
+	}
+
+	done := func(error) {}
+	if tree, ok := ctx.Value(callTreeKey{}).(*CallTree); ok {
+		var pc *Call
+		if parent != nil {
+			pc = parent.call
+		}
+		frame.call = tree.add(pc, rqt)
+		done = func(err error) { tree.complete(frame.call, err) }
+	}
+
+	var err error
+	for f := parent; f != nil && err == nil; f = f.parent {
+		if f.rqt == rqt {
+			err = &CommandCycleError{chain: frame.chain()}
+		}
+	}
+	if err == nil && maxExecutionDepth > 0 && frame.depth > maxExecutionDepth {
+		err = &ExecutionDepthError{max: maxExecutionDepth, chain: frame.chain()}
+	}
+	if err != nil {
+		done(err)
+		return ctx, nil, err
+	}
+
+	return context.WithValue(ctx, executionFrameKey{}, frame), done, nil
+}
+
+// Call records the execution of a request in a CallTree.
+type Call struct {
+	RequestType reflect.Type  // the type of the request
+	Start       time.Time     // the time at which execution started
+	Duration    time.Duration // the time taken to execute the request (zero if not complete)
+	Err         error         // any error returned
+	Calls       []*Call       // the nested requests executed by the command
+}
+
+// CallTree records the requests executed using a context, and the nested
+// requests executed by their commands, for tracing.  A CallTree is safe for
+// concurrent use; the Calls of the tree should be examined only when
+// execution of the requests is complete.
+type CallTree struct {
+	mu    sync.Mutex
+	calls []*Call
+}
+
+// callTreeKey is the context key for a CallTree.
+type callTreeKey struct{}
+
+// ContextWithCallTree returns a context with a new CallTree, recording the
+// requests executed using the context:
+//
+//	ctx, tree := mediator.ContextWithCallTree(ctx)
+//	_, err := mediator.Execute(ctx, rq, mediator.NoResult)
+//	log.Print(tree)
+func ContextWithCallTree(ctx context.Context) (context.Context, *CallTree) {
+	tree := &CallTree{}
+	return context.WithValue(ctx, callTreeKey{}, tree), tree
+}
+
+// Calls returns the requests executed using the context with which the tree
+// was established.
+func (tree *CallTree) Calls() []*Call {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	return append([]*Call{}, tree.calls...)
+}
+
+// add records the start of the execution of a request of the specified type,
+// nested in the specified call (if not nil).
+func (tree *CallTree) add(parent *Call, rqt reflect.Type) *Call {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	call := &Call{RequestType: rqt, Start: time.Now()}
+	if parent == nil {
+		tree.calls = append(tree.calls, call)
+	} else {
+		parent.Calls = append(parent.Calls, call)
+	}
+	return call
+}
+
+// complete records the outcome of a call.
+func (tree *CallTree) complete(call *Call, err error) {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	call.Duration = time.Since(call.Start)
+	call.Err = err
+}
+
+// String returns a representation of the tree, with each call on a separate
+// line, indented to show nested calls.
+func (tree *CallTree) String() string {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	b := &strings.Builder{}
+	var write func(calls []*Call, indent string)
+	write = func(calls []*Call, indent string) {
+		for _, call := range calls {
+			fmt.Fprintf(b, "%s%s (%v)", indent, typeName(call.RequestType), call.Duration)
+			if call.Err != nil {
+				fmt.Fprintf(b, ": %v", call.Err)
+			}
+			b.WriteString("\n")
+			write(call.Calls, indent+"  ")
+		}
+	}
+	write(tree.calls, "")
+	return b.String()
+}
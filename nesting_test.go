@@ -0,0 +1,201 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// nestingtesta, nestingtestb and nestingtestc are request types used for
+// testing nested requests.
+type nestingtesta struct{}
+type nestingtestb struct{}
+type nestingtestc struct{}
+
+func TestNestedExecution(t *testing.T) {
+	// ARRANGE
+	ta, tb, tc := reflect.TypeOf(nestingtesta{}), reflect.TypeOf(nestingtestb{}), reflect.TypeOf(nestingtestc{})
+
+	t.Run("execution chain", func(t *testing.T) {
+		// ARRANGE
+		ctx := testContext(t)
+		var chain []reflect.Type
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ nestingtesta) (NoResultType, error) {
+			return Execute(ctx, nestingtestb{}, NoResult)
+		})
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ nestingtestb) (NoResultType, error) {
+			chain = ExecutionChain(ctx)
+			return nil, nil
+		})
+
+		// ACT
+		_, err := Execute(ctx, nestingtesta{}, NoResult)
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wanted := []reflect.Type{ta, tb}
+		got := chain
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		// ARRANGE
+		ctx := testContext(t)
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ nestingtesta) (NoResultType, error) {
+			return Execute(ctx, nestingtestb{}, NoResult)
+		})
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ nestingtestb) (NoResultType, error) {
+			return Execute(ctx, nestingtesta{}, NoResult)
+		})
+
+		// ACT
+		_, err := Execute(ctx, nestingtesta{}, NoResult)
+
+		// ASSERT
+		cycle := &CommandCycleError{}
+		if !errors.As(err, &cycle) {
+			t.Fatalf("\nwanted CommandCycleError\ngot    %#v", err)
+		}
+		wanted := []reflect.Type{ta, tb, ta}
+		got := cycle.Chain()
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("maximum depth", func(t *testing.T) {
+		// ARRANGE
+		ctx := testContext(t)
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ nestingtesta) (NoResultType, error) {
+			return Execute(ctx, nestingtestb{}, NoResult)
+		})
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ nestingtestb) (NoResultType, error) {
+			return Execute(ctx, nestingtestc{}, NoResult)
+		})
+		_ = mockCommandT[nestingtestc, NoResultType](t)
+
+		defer SetMaxExecutionDepth(DefaultMaxExecutionDepth)
+
+		testcases := []struct {
+			depth int
+			err   error
+		}{
+			{depth: 0},
+			{depth: 3},
+			{depth: 2, err: ExecutionDepthError{}},
+		}
+		for _, tc := range testcases {
+			t.Run(fmt.Sprintf("depth %d", tc.depth), func(t *testing.T) {
+				// ARRANGE
+				SetMaxExecutionDepth(tc.depth)
+
+				// ACT
+				_, err := Execute(ctx, nestingtesta{}, NoResult)
+
+				// ASSERT
+				wanted := tc.err
+				got := err
+				if (wanted == nil && got != nil) || !errors.Is(got, wanted) {
+					t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+				}
+			})
+		}
+	})
+
+	t.Run("call tree", func(t *testing.T) {
+		// ARRANGE
+		ctx := testContext(t)
+		cerr := errors.New("c failed")
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ nestingtesta) (NoResultType, error) {
+			_, _ = Execute(ctx, nestingtestb{}, NoResult)
+			return Execute(ctx, nestingtestc{}, NoResult)
+		})
+		_ = mockCommandFuncT(t, nil, func(ctx context.Context, _ nestingtestb) (NoResultType, error) {
+			return Execute(ctx, nestingtesta{}, NoResult)
+		})
+		_ = mockCommandErrorT[nestingtestc, NoResultType](t, cerr)
+
+		ctx, tree := ContextWithCallTree(ctx)
+
+		// ACT
+		_, _ = Execute(ctx, nestingtesta{}, NoResult)
+
+		// ASSERT
+		type call struct {
+			rqt   reflect.Type
+			err   bool
+			calls []call
+		}
+		var simplify func([]*Call) []call
+		simplify = func(calls []*Call) []call {
+			var result []call
+			for _, c := range calls {
+				result = append(result, call{rqt: c.RequestType, err: c.Err != nil, calls: simplify(c.Calls)})
+			}
+			return result
+		}
+
+		wanted := []call{
+			{rqt: ta, err: true, calls: []call{
+				{rqt: tb, err: true, calls: []call{
+					{rqt: ta, err: true},
+				}},
+				{rqt: tc, err: true},
+			}},
+		}
+		got := simplify(tree.Calls())
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+
+		t.Run("String()", func(t *testing.T) {
+			lines := strings.Split(strings.TrimSpace(tree.String()), "\n")
+			wanted := []string{
+				"github.com/blugnu/mediator.nestingtesta",
+				"  github.com/blugnu/mediator.nestingtestb",
+				"    github.com/blugnu/mediator.nestingtesta",
+				"  github.com/blugnu/mediator.nestingtestc",
+			}
+			got := make([]string, len(lines))
+			for i, line := range lines {
+				got[i] = line[:strings.Index(line, " (")]
+			}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+	t.Run("call tree when the command panics", func(t *testing.T) {
+		// ARRANGE
+		ctx := testContext(t)
+		_ = mockCommandFuncT(t, nil, func(context.Context, nestingtesta) (NoResultType, error) {
+			panic("command panicked")
+		})
+
+		ctx, tree := ContextWithCallTree(ctx)
+
+		// ACT
+		func() {
+			defer func() { _ = recover() }()
+			_, _ = Execute(ctx, nestingtesta{}, NoResult)
+		}()
+
+		// ASSERT
+		calls := tree.Calls()
+		if len(calls) != 1 {
+			t.Fatalf("\nwanted 1 call\ngot    %d", len(calls))
+		}
+		wanted := true
+		got := errors.As(calls[0].Err, new(*PanicError))
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v (%v)", wanted, got, calls[0].Err)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package mediator
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// NotificationHandler[TNotification] is the interface that must be implemented
+// by a handler of notifications of a particular type.
+//
+// Unlike commands, any number of handlers may be registered for a notification
+// type; a notification is published to all of them.
+type NotificationHandler[TNotification any] interface {
+	Handle(context.Context, TNotification) error
+}
+
+// notificationHandler is a registered NotificationHandler[TNotification] with
+// a function which passes a notification (of the type handled) to it.
+type notificationHandler struct {
+	handler any
+	handle  func(context.Context, any) error
+}
+
+// notifications holds the handlers registered for each notification type.
+//
+// types maps the name of each notification type for which a handler is
+// registered to the type itself.  This enables notifications to be
+// reconstructed from their name and encoded form (e.g. by the outbox
+// dispatcher).
+var notifications = struct {
+	sync.RWMutex
+	handlers map[reflect.Type][]*notificationHandler
+	types    map[string]reflect.Type
+}{
+	handlers: map[reflect.Type][]*notificationHandler{},
+	types:    map[string]reflect.Type{},
+}
+
+// RegisterNotificationHandler[TNotification] registers a handler for
+// notifications of the specified type.
+//
+// If the handler implements the ConfigurationChecker interface, this is
+// called and any error returned; the handler is not registered.
+func RegisterNotificationHandler[TNotification any](ctx context.Context, h NotificationHandler[TNotification]) error {
+	_, err := registerNotificationHandler(ctx, h)
+	return err
+}
+
+// registerNotificationHandler registers a notification handler, returning a
+// function which removes the registration.
+func registerNotificationHandler[TNotification any](ctx context.Context, h NotificationHandler[TNotification]) (func(), error) {
+	if cfg, ok := h.(ConfigurationChecker); ok {
+		if err := cfg.CheckConfiguration(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	nt := reflect.TypeOf(*new(TNotification))
+	reg := &notificationHandler{
+		handler: h,
+		handle: func(ctx context.Context, n any) error {
+			return h.Handle(ctx, n.(TNotification))
+		},
+	}
+
+	notifications.Lock()
+	defer notifications.Unlock()
+
+	notifications.handlers[nt] = append(notifications.handlers[nt], reg)
+	notifications.types[typeName(nt)] = nt
+
+	return func() {
+		notifications.Lock()
+		defer notifications.Unlock()
+
+		hs := notifications.handlers[nt]
+		for i, each := range hs {
+			if each == reg {
+				notifications.handlers[nt] = append(hs[:i:i], hs[i+1:]...)
+				break
+			}
+		}
+		if len(notifications.handlers[nt]) == 0 {
+			delete(notifications.handlers, nt)
+			delete(notifications.types, typeName(nt))
+		}
+	}, nil
+}
+
+// Publish[TNotification] publishes a notification to all handlers registered
+// for the notification type, in the order in which they were registered.
+//
+// If a handler returns an error, the error is returned and the notification
+// is not published to any remaining handlers.  If no handlers are registered
+// for the notification type, the function does nothing.
+func Publish[TNotification any](ctx context.Context, n TNotification) error {
+	return publish(ctx, reflect.TypeOf(n), n)
+}
+
+// publish publishes a notification of the specified type to all handlers
+// registered for that type when it is called.
+func publish(ctx context.Context, nt reflect.Type, n any) error {
+	notifications.RLock()
+	hs := notifications.handlers[nt]
+	notifications.RUnlock()
+
+	for _, h := range hs {
+		if err := h.handle(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// notificationtesthandler is a notification handler used for testing
+// notifications.
+type notificationtesthandler struct {
+	name     string
+	log      *[]string
+	cfgerr   error
+	err      error
+	received []complex64
+}
+
+func (h *notificationtesthandler) CheckConfiguration(context.Context) error { return h.cfgerr }
+
+func (h *notificationtesthandler) Handle(_ context.Context, n complex64) error {
+	h.received = append(h.received, n)
+	if h.log != nil {
+		*h.log = append(*h.log, h.name)
+	}
+	return h.err
+}
+
+func TestRegisterNotificationHandler(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	nt := reflect.TypeOf(complex64(0))
+
+	t.Run("returns any ConfigurationChecker error", func(t *testing.T) {
+		// ARRANGE
+		cfgerr := errors.New("configuration error")
+
+		// ACT
+		err := RegisterNotificationHandler[complex64](ctx, &notificationtesthandler{cfgerr: cfgerr})
+
+		// ASSERT
+		wanted := cfgerr
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+
+		t.Run("does not register the handler", func(t *testing.T) {
+			if len(notifications.handlers[nt]) > 0 {
+				t.Error("handler was registered")
+			}
+		})
+	})
+
+	t.Run("registering handlers", func(t *testing.T) {
+		// ACT
+		unreg1, _ := registerNotificationHandler[complex64](ctx, &notificationtesthandler{})
+		unreg2, _ := registerNotificationHandler[complex64](ctx, &notificationtesthandler{})
+
+		// ASSERT
+		t.Run("adds handlers to registry", func(t *testing.T) {
+			wanted := 2
+			got := len(notifications.handlers[nt])
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("registers notification type name", func(t *testing.T) {
+			wanted := nt
+			got := notifications.types["complex64"]
+			if wanted != got {
+				t.Errorf("\nwanted %v\ngot    %v", wanted, got)
+			}
+		})
+
+		t.Run("returns funcs which remove the handlers", func(t *testing.T) {
+			// ACT
+			unreg1()
+			unreg2()
+
+			// ASSERT
+			if _, ok := notifications.handlers[nt]; ok {
+				t.Error("notification type remains in handler registry")
+			}
+			if _, ok := notifications.types["complex64"]; ok {
+				t.Error("notification type remains in type registry")
+			}
+		})
+	})
+}
+
+func TestPublish(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	t.Run("when no handlers are registered", func(t *testing.T) {
+		// ACT
+		err := Publish(ctx, complex64(1))
+
+		// ASSERT
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("publishes to all handlers in order", func(t *testing.T) {
+		// ARRANGE
+		log := []string{}
+		unreg1, _ := registerNotificationHandler[complex64](ctx, &notificationtesthandler{name: "first", log: &log})
+		defer unreg1()
+		unreg2, _ := registerNotificationHandler[complex64](ctx, &notificationtesthandler{name: "second", log: &log})
+		defer unreg2()
+
+		// ACT
+		err := Publish(ctx, complex64(1))
+
+		// ASSERT
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		wanted := []string{"first", "second"}
+		got := log
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when a handler returns an error", func(t *testing.T) {
+		// ARRANGE
+		log := []string{}
+		herr := errors.New("handler error")
+		unreg1, _ := registerNotificationHandler[complex64](ctx, &notificationtesthandler{name: "first", log: &log, err: herr})
+		defer unreg1()
+		unreg2, _ := registerNotificationHandler[complex64](ctx, &notificationtesthandler{name: "second", log: &log})
+		defer unreg2()
+
+		// ACT
+		err := Publish(ctx, complex64(1))
+
+		// ASSERT
+		t.Run("returns the error", func(t *testing.T) {
+			wanted := herr
+			got := err
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("does not publish to remaining handlers", func(t *testing.T) {
+			wanted := []string{"first"}
+			got := log
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+}
+
+func TestThatNotificationHandlersAreSafeForConcurrentUse(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	done := make(chan struct{})
+
+	// ACT
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			unregister, _ := registerNotificationHandler[complex128](ctx, notificationfunc128(func(context.Context, complex128) error { return nil }))
+			unregister()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_ = Publish(ctx, complex128(0))
+		notifications.RLock()
+		_ = notifications.types["complex128"]
+		notifications.RUnlock()
+	}
+	<-done
+
+	// ASSERT
+	notifications.RLock()
+	defer notifications.RUnlock()
+	if _, ok := notifications.handlers[reflect.TypeOf(complex128(0))]; ok {
+		t.Error("handler was not unregistered")
+	}
+}
+
+// notificationfunc128 is a function handling complex128 notifications.
+type notificationfunc128 func(context.Context, complex128) error
+
+func (fn notificationfunc128) Handle(ctx context.Context, n complex128) error { return fn(ctx, n) }
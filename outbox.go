@@ -0,0 +1,285 @@
+package mediator
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrNoOutbox is returned by Enqueue if no OutboxStore has been established
+// using SetOutboxStore.
+var ErrNoOutbox = errors.New("no outbox store established")
+
+// ErrNoUnitOfWork is returned by Enqueue if the context is not associated
+// with a unit of work, i.e. Enqueue was not called by a Transactional command
+// (or by a command executed by a Transactional command).
+var ErrNoUnitOfWork = errors.New("no unit of work")
+
+// OutboxMessage is a notification stored in an outbox, awaiting delivery.
+type OutboxMessage struct {
+	ID            string          // uniquely identifies the message
+	Type          string          // the name of the notification type
+	Payload       json.RawMessage // the notification, encoded as JSON
+	CreatedAt     time.Time       // the time at which the notification was enqueued
+	Attempts      int             // the number of failed attempts to deliver the message
+	LastError     string          // the error from the most recent failed attempt
+	NextAttemptAt time.Time       // the time after which delivery should next be attempted
+	Abandoned     bool            // true if no further attempts will be made to deliver the message
+}
+
+// OutboxStore is the interface that must be implemented by an outbox which
+// stores notifications enqueued by commands until they are delivered.
+type OutboxStore interface {
+	// Save stores messages using the specified transaction.  The messages are
+	// therefore stored only if the transaction is committed.
+	Save(ctx context.Context, tx *sql.Tx, msgs []OutboxMessage) error
+
+	// Pending returns up to the specified number of messages that have not
+	// been delivered or abandoned and for which NextAttemptAt is not after the
+	// specified time, in the order in which they were created.
+	Pending(ctx context.Context, at time.Time, limit int) ([]OutboxMessage, error)
+
+	// Delivered records that the message with the specified ID was delivered.
+	// A delivered message must not be returned by Pending.
+	Delivered(ctx context.Context, id string) error
+
+	// Failed records a failed attempt to deliver a message, updating the
+	// Attempts, LastError, NextAttemptAt and Abandoned fields of the stored
+	// message with those of the specified message.
+	Failed(ctx context.Context, msg OutboxMessage) error
+}
+
+var outbox OutboxStore
+
+// SetOutboxStore establishes the store used to hold notifications enqueued
+// by commands.
+func SetOutboxStore(store OutboxStore) {
+	outbox = store
+}
+
+// Enqueue[TNotification] adds a notification to the outbox of the unit of
+// work associated with the specified context.  The notification is stored in
+// the outbox when the unit of work is committed, using the same transaction;
+// if the unit of work is rolled back the notification is discarded.
+//
+// Stored notifications are published to notification handlers by an
+// OutboxDispatcher.
+//
+// The notification is encoded as JSON when it is enqueued; any encoding
+// error is returned.  If there is no unit of work associated with the context,
+// ErrNoUnitOfWork is returned.  If no OutboxStore has been established,
+// ErrNoOutbox is returned.
+func Enqueue[TNotification any](ctx context.Context, n TNotification) error {
+	if outbox == nil {
+		return ErrNoOutbox
+	}
+
+	uow, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if !ok {
+		return ErrNoUnitOfWork
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	uow.enqueue(OutboxMessage{
+		ID:        newID(),
+		Type:      typeName(reflect.TypeOf(n)),
+		Payload:   payload,
+		CreatedAt: now(),
+	})
+	return nil
+}
+
+// outboxMessages holds messages enqueued in a unit of work.
+type outboxMessages struct {
+	mu   sync.Mutex
+	msgs []OutboxMessage
+}
+
+// enqueue adds a message to the outbox of a unit of work.
+func (uow *unitOfWork) enqueue(msg OutboxMessage) {
+	uow.outbox.mu.Lock()
+	defer uow.outbox.mu.Unlock()
+
+	uow.outbox.msgs = append(uow.outbox.msgs, msg)
+}
+
+// saveOutbox saves any messages enqueued in the unit of work to the outbox
+// store, using the transaction of the unit of work.
+func (uow *unitOfWork) saveOutbox(ctx context.Context) error {
+	uow.outbox.mu.Lock()
+	defer uow.outbox.mu.Unlock()
+
+	if len(uow.outbox.msgs) == 0 {
+		return nil
+	}
+	if outbox == nil {
+		return ErrNoOutbox
+	}
+	return outbox.Save(ctx, uow.tx, uow.outbox.msgs)
+}
+
+// newID returns a new, random identifier.
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("mediator: unable to generate id: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// UndeliverableError is recorded as the LastError of an OutboxMessage
+// that cannot be decoded because there is no handler registered for the
+// notification type.
+type UndeliverableError struct {
+	Type string
+}
+
+func (e UndeliverableError) Error() string {
+	return fmt.Sprintf("no notification handler registered for: %s", e.Type)
+}
+
+// OutboxDispatcher publishes notifications stored in an outbox to the
+// handlers registered for each notification type.
+//
+// Delivery is at-least-once: a message is recorded as delivered only
+// after it has been published to all handlers without error.  If any handler
+// returns an error, the failed attempt is recorded and delivery retried
+// (to all handlers) after a delay determined by the Backoff function.
+// Notification handlers must therefore be idempotent.
+type OutboxDispatcher struct {
+	// Store is the outbox from which messages are dispatched; if nil, the
+	// store established by SetOutboxStore is used.
+	Store OutboxStore
+
+	// BatchSize is the maximum number of messages dispatched by each call to
+	// Dispatch; if zero, a default of 100 is used.
+	BatchSize int
+
+	// MaxAttempts is the number of failed attempts after which a message is
+	// abandoned; if zero, messages are never abandoned.
+	MaxAttempts int
+
+	// Backoff returns the delay before the next attempt to deliver a message
+	// which has failed the specified number of times.  If nil, the delay
+	// doubles with each attempt, from 1 second to a maximum of 1 hour.
+	Backoff func(attempts int) time.Duration
+}
+
+// Dispatch publishes a batch of pending messages from the outbox, returning
+// the number of messages delivered.
+//
+// Failures to publish a message are recorded in the store and do not result
+// in an error.  An error is returned only if the store returns an error.
+func (d *OutboxDispatcher) Dispatch(ctx context.Context) (int, error) {
+	store := d.Store
+	if store == nil {
+		store = outbox
+	}
+	if store == nil {
+		return 0, ErrNoOutbox
+	}
+
+	limit := d.BatchSize
+	if limit <= 0 {
+		limit = 100
+	}
+
+	msgs, err := store.Pending(ctx, now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, msg := range msgs {
+		if err := d.deliver(ctx, msg); err != nil {
+			if err := store.Failed(ctx, d.failed(msg, err)); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := store.Delivered(ctx, msg.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// Run dispatches pending messages from the outbox at the specified interval
+// until the context is cancelled or the store returns an error.  The error
+// is returned; if the context is cancelled, the context error is returned.
+func (d *OutboxDispatcher) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		if _, err := d.Dispatch(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// deliver decodes the notification in a message and publishes it.
+func (d *OutboxDispatcher) deliver(ctx context.Context, msg OutboxMessage) error {
+	notifications.RLock()
+	nt, ok := notifications.types[msg.Type]
+	notifications.RUnlock()
+	if !ok {
+		return UndeliverableError{Type: msg.Type}
+	}
+
+	n := reflect.New(nt)
+	if err := json.Unmarshal(msg.Payload, n.Interface()); err != nil {
+		return err
+	}
+	return publish(ctx, nt, n.Elem().Interface())
+}
+
+// failed returns a copy of the message updated to record a failed attempt
+// to deliver it.
+func (d *OutboxDispatcher) failed(msg OutboxMessage, err error) OutboxMessage {
+	msg.Attempts++
+	msg.LastError = err.Error()
+
+	if d.MaxAttempts > 0 && msg.Attempts >= d.MaxAttempts {
+		msg.Abandoned = true
+		msg.NextAttemptAt = time.Time{}
+		return msg
+	}
+
+	backoff := d.Backoff
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+	msg.NextAttemptAt = now().Add(backoff(msg.Attempts))
+	return msg
+}
+
+// defaultBackoff returns a delay which doubles with each attempt, from 1
+// second to a maximum of 1 hour.
+func defaultBackoff(attempts int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempts && d < time.Hour; i++ {
+		d *= 2
+	}
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
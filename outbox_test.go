@@ -0,0 +1,404 @@
+package mediator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// outboxteststore is an in-memory OutboxStore used for testing the outbox.
+type outboxteststore struct {
+	msgs      map[string]*OutboxMessage
+	delivered []string
+	saved     []*sql.Tx
+	saveerr   error
+}
+
+func (s *outboxteststore) Save(_ context.Context, tx *sql.Tx, msgs []OutboxMessage) error {
+	if s.saveerr != nil {
+		return s.saveerr
+	}
+	if s.msgs == nil {
+		s.msgs = map[string]*OutboxMessage{}
+	}
+	s.saved = append(s.saved, tx)
+	for _, msg := range msgs {
+		msg := msg
+		s.msgs[msg.ID] = &msg
+	}
+	return nil
+}
+
+func (s *outboxteststore) Pending(_ context.Context, at time.Time, limit int) ([]OutboxMessage, error) {
+	result := []OutboxMessage{}
+	for _, msg := range s.msgs {
+		if !msg.Abandoned && !msg.NextAttemptAt.After(at) {
+			result = append(result, *msg)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *outboxteststore) Delivered(_ context.Context, id string) error {
+	s.delivered = append(s.delivered, id)
+	delete(s.msgs, id)
+	return nil
+}
+
+func (s *outboxteststore) Failed(_ context.Context, msg OutboxMessage) error {
+	s.msgs[msg.ID] = &msg
+	return nil
+}
+
+// outboxtestnotification is a notification used for testing the outbox.
+type outboxtestnotification struct {
+	Id string
+}
+
+// outboxtesthandler is a notification handler used for testing the outbox.
+type outboxtesthandler struct {
+	received []outboxtestnotification
+	err      error
+}
+
+func (h *outboxtesthandler) Handle(_ context.Context, n outboxtestnotification) error {
+	h.received = append(h.received, n)
+	return h.err
+}
+
+// outboxtestcmd is a Transactional command which enqueues notifications.
+type outboxtestcmd struct {
+	ids []string
+	err error
+}
+
+func (cmd *outboxtestcmd) TxOptions() *sql.TxOptions { return nil }
+
+func (cmd *outboxtestcmd) Execute(ctx context.Context, _ uint16) (NoResultType, error) {
+	for _, id := range cmd.ids {
+		if err := Enqueue(ctx, outboxtestnotification{Id: id}); err != nil {
+			return nil, err
+		}
+	}
+	return nil, cmd.err
+}
+
+func TestEnqueue(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	t.Run("when no outbox store is established", func(t *testing.T) {
+		// ACT
+		err := Enqueue(ctx, outboxtestnotification{})
+
+		// ASSERT
+		wanted := ErrNoOutbox
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when there is no unit of work", func(t *testing.T) {
+		// ARRANGE
+		SetOutboxStore(&outboxteststore{})
+		defer SetOutboxStore(nil)
+
+		// ACT
+		err := Enqueue(ctx, outboxtestnotification{})
+
+		// ASSERT
+		wanted := ErrNoUnitOfWork
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when notification cannot be encoded", func(t *testing.T) {
+		// ARRANGE
+		SetOutboxStore(&outboxteststore{})
+		defer SetOutboxStore(nil)
+		ctx := context.WithValue(ctx, unitOfWorkKey{}, &unitOfWork{})
+
+		// ACT
+		err := Enqueue(ctx, func() {})
+
+		// ASSERT
+		if err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestOutbox(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	arrange := func(t *testing.T, cmd *outboxtestcmd) (*uowtestdb, *outboxteststore) {
+		t.Helper()
+		db := &uowtestdb{}
+		store := &outboxteststore{}
+		SetDatabase(sql.OpenDB(db))
+		SetOutboxStore(store)
+		unreg, err := register(ctx, uint16(0), cmd)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		t.Cleanup(func() { SetDatabase(nil); SetOutboxStore(nil); unreg() })
+		return db, store
+	}
+
+	t.Run("when command succeeds", func(t *testing.T) {
+		// ARRANGE
+		db, store := arrange(t, &outboxtestcmd{ids: []string{"a", "b"}})
+
+		// ACT
+		_, err := Execute(ctx, uint16(1), NoResult)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		t.Run("saves notifications in a single transaction", func(t *testing.T) {
+			wanted := 1
+			got := len(store.saved)
+			if wanted != got || store.saved[0] == nil {
+				t.Errorf("\nwanted %d save with transaction\ngot    %v", wanted, store.saved)
+			}
+		})
+
+		t.Run("saves enqueued notifications", func(t *testing.T) {
+			wanted := 2
+			got := len(store.msgs)
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+			for _, msg := range store.msgs {
+				if msg.Type != "github.com/blugnu/mediator.outboxtestnotification" {
+					t.Errorf("unexpected message type: %s", msg.Type)
+				}
+			}
+		})
+
+		t.Run("commits the transaction", func(t *testing.T) {
+			if db.committed != 1 {
+				t.Errorf("wanted 1 commit, got %d", db.committed)
+			}
+		})
+	})
+
+	t.Run("when command fails", func(t *testing.T) {
+		// ARRANGE
+		db, store := arrange(t, &outboxtestcmd{ids: []string{"a"}, err: errors.New("command failed")})
+
+		// ACT
+		_, _ = Execute(ctx, uint16(1), NoResult)
+
+		// ASSERT
+		if len(store.msgs) > 0 {
+			t.Errorf("wanted no messages saved, got %d", len(store.msgs))
+		}
+		if db.rolledback != 1 {
+			t.Errorf("wanted 1 rollback, got %d", db.rolledback)
+		}
+	})
+
+	t.Run("when outbox cannot be saved", func(t *testing.T) {
+		// ARRANGE
+		db, store := arrange(t, &outboxtestcmd{ids: []string{"a"}})
+		store.saveerr = errors.New("save failed")
+
+		// ACT
+		_, err := Execute(ctx, uint16(1), NoResult)
+
+		// ASSERT
+		wanted := store.saveerr
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+		if db.committed != 0 || db.rolledback != 1 {
+			t.Errorf("wanted rollback, got %d commits, %d rollbacks", db.committed, db.rolledback)
+		}
+	})
+}
+
+func TestOutboxDispatcher(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	onow := now
+	defer func() { now = onow }()
+	at := time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+	now = func() time.Time { return at }
+
+	message := func(id string) OutboxMessage {
+		return OutboxMessage{
+			ID:        id,
+			Type:      "github.com/blugnu/mediator.outboxtestnotification",
+			Payload:   []byte(`{"Id":"` + id + `"}`),
+			CreatedAt: at,
+		}
+	}
+
+	t.Run("when no store is established", func(t *testing.T) {
+		// ARRANGE
+		sut := &OutboxDispatcher{}
+
+		// ACT
+		_, err := sut.Dispatch(ctx)
+
+		// ASSERT
+		wanted := ErrNoOutbox
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when delivery succeeds", func(t *testing.T) {
+		// ARRANGE
+		h := &outboxtesthandler{}
+		unreg, _ := registerNotificationHandler[outboxtestnotification](ctx, h)
+		defer unreg()
+
+		store := &outboxteststore{}
+		_ = store.Save(ctx, nil, []OutboxMessage{message("a")})
+		sut := &OutboxDispatcher{Store: store}
+
+		// ACT
+		n, err := sut.Dispatch(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		t.Run("publishes the notification", func(t *testing.T) {
+			wanted := []outboxtestnotification{{Id: "a"}}
+			got := h.received
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("records delivery", func(t *testing.T) {
+			if n != 1 || !reflect.DeepEqual(store.delivered, []string{"a"}) {
+				t.Errorf("wanted 1 delivered (a), got %d (%v)", n, store.delivered)
+			}
+		})
+	})
+
+	t.Run("when delivery fails", func(t *testing.T) {
+		// ARRANGE
+		herr := errors.New("handler error")
+		h := &outboxtesthandler{err: herr}
+		unreg, _ := registerNotificationHandler[outboxtestnotification](ctx, h)
+		defer unreg()
+
+		store := &outboxteststore{}
+		_ = store.Save(ctx, nil, []OutboxMessage{message("a")})
+		sut := &OutboxDispatcher{Store: store, MaxAttempts: 3}
+
+		// ACT
+		n, err := sut.Dispatch(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		msg := store.msgs["a"]
+		t.Run("records failed attempt", func(t *testing.T) {
+			if n != 0 || msg.Attempts != 1 || msg.LastError != herr.Error() || msg.Abandoned {
+				t.Errorf("wanted 1 failed attempt, got %d delivered, %#v", n, msg)
+			}
+		})
+
+		t.Run("schedules next attempt", func(t *testing.T) {
+			wanted := at.Add(time.Second)
+			got := msg.NextAttemptAt
+			if !wanted.Equal(got) {
+				t.Errorf("\nwanted %v\ngot    %v", wanted, got)
+			}
+		})
+
+		t.Run("does not retry before next attempt", func(t *testing.T) {
+			// ACT
+			_, _ = sut.Dispatch(ctx)
+
+			// ASSERT
+			wanted := 1
+			got := len(h.received)
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("abandons message after max attempts", func(t *testing.T) {
+			// ARRANGE
+			defer func() { now = func() time.Time { return at } }()
+
+			// ACT
+			for i := 1; i <= 3; i++ {
+				later := at.Add(time.Duration(i) * time.Hour)
+				now = func() time.Time { return later }
+				_, _ = sut.Dispatch(ctx)
+			}
+
+			// ASSERT
+			msg := store.msgs["a"]
+			if msg.Attempts != 3 || !msg.Abandoned || len(h.received) != 3 {
+				t.Errorf("wanted 3 attempts and abandoned, got %#v (%d received)", msg, len(h.received))
+			}
+		})
+	})
+
+	t.Run("when notification type is not registered", func(t *testing.T) {
+		// ARRANGE
+		store := &outboxteststore{}
+		_ = store.Save(ctx, nil, []OutboxMessage{message("a")})
+		sut := &OutboxDispatcher{Store: store}
+
+		// ACT
+		_, _ = sut.Dispatch(ctx)
+
+		// ASSERT
+		wanted := UndeliverableError{Type: "github.com/blugnu/mediator.outboxtestnotification"}.Error()
+		got := store.msgs["a"].LastError
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	testcases := []struct {
+		attempts int
+		result   time.Duration
+	}{
+		{attempts: 1, result: time.Second},
+		{attempts: 2, result: 2 * time.Second},
+		{attempts: 5, result: 16 * time.Second},
+		{attempts: 100, result: time.Hour},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.result.String(), func(t *testing.T) {
+			// ACT
+			got := defaultBackoff(tc.attempts)
+
+			// ASSERT
+			wanted := tc.result
+			if wanted != got {
+				t.Errorf("\nwanted %v\ngot    %v", wanted, got)
+			}
+		})
+	}
+}
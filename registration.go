@@ -3,10 +3,89 @@ package mediator
 import (
 	"context"
 	"reflect"
+	"sort"
 )
 
 var commands = map[reflect.Type]any{}
 
+// lookup returns the command registered for the specified request type in
+// the registry bound to the context, if any, otherwise the command registered
+// for the tenant identified by the context, if any, otherwise the command
+// registered in the global registry.
+func lookup(ctx context.Context, rqt reflect.Type) (any, bool) {
+	if r, ok := ctx.Value(registryKey{}).(*Registry); ok {
+		if cmd, ok := r.commands.lookup(commandKey{rqt: rqt}); ok {
+			return cmd, true
+		}
+	}
+	if cmd, ok := lookupTenant(ctx, rqt); ok {
+		return cmd, true
+	}
+	cmd, ok := commands[rqt]
+	return cmd, ok
+}
+
+// nearMisses returns the types, other than the specified request type, for
+// which commands are registered that the caller may have intended: types
+// differing from the request type only by indirection (i.e. T for requests of
+// type *T, or *T for requests of type T) and types with the same name declared
+// in other packages.  The types are sorted by name.
+func nearMisses(ctx context.Context, rqt reflect.Type) []reflect.Type {
+	if rqt == nil {
+		return nil
+	}
+
+	base := func(t reflect.Type) reflect.Type {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		return t
+	}
+
+	found := map[reflect.Type]bool{}
+	t := reflect.PointerTo(rqt)
+	if rqt.Kind() == reflect.Pointer {
+		t = rqt.Elem()
+	}
+	if _, ok := lookup(ctx, t); ok {
+		found[t] = true
+	}
+	if name := base(rqt).Name(); name != "" {
+		for t := range commands {
+			if t != rqt && base(t).Name() == name {
+				found[t] = true
+			}
+		}
+	}
+
+	result := make([]reflect.Type, 0, len(found))
+	for t := range found {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return typeName(result[i]) < typeName(result[j]) })
+	return result
+}
+
+// wrapper is implemented by a command which wraps another command (e.g. a
+// command established using WrapCommand).
+type wrapper interface {
+	wrapped() any
+}
+
+// unwrap returns the command wrapped by the specified command, if it is a
+// wrapper, otherwise the command itself.  Optional interfaces which determine
+// how a request is executed (e.g. Authorizer and Transactional) are those of
+// the wrapped command.
+func unwrap(cmd any) any {
+	for {
+		w, ok := cmd.(wrapper)
+		if !ok {
+			return cmd
+		}
+		cmd = w.wrapped()
+	}
+}
+
 // register provides the function used to register a command.  This is called
 // by RegisterCommand and the mock command factories.
 //
@@ -41,7 +120,10 @@ var register = func(ctx context.Context, rq any, cmd any) (func(), error) {
 //
 // If the command does not implementation ConfigurationChecker or the configuration
 // check returns no error, then the command is registered.
+//
+// Any OnRegister hooks established using AddHooks are called with the outcome.
 func RegisterCommand[TRequest any, TResult any](ctx context.Context, cmd CommandHandler[TRequest, TResult]) error {
 	_, err := register(ctx, *new(TRequest), cmd)
+	fireHook(ctx, onRegister, RegistrationEvent{RequestType: reflect.TypeOf(*new(TRequest)), Handler: cmd, Err: err})
 	return err
 }
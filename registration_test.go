@@ -1,10 +1,13 @@
 package mediator
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // registrationtestcmd is a command used for testing the registration function.
@@ -15,6 +18,38 @@ type registrationtestcmd struct {
 func (cmd registrationtestcmd) CheckConfiguration(context.Context) error       { return cmd.cfgerr }
 func (registrationtestcmd) Execute(context.Context, int) (NoResultType, error) { return nil, nil }
 
+// registrationexecutingtestcmd is a command with a configuration check which
+// executes a request, used for testing that registrations do not hold locks
+// required to execute requests.
+type registrationexecutingtestcmd struct {
+	exec func(context.Context)
+}
+
+func (cmd registrationexecutingtestcmd) CheckConfiguration(ctx context.Context) error {
+	cmd.exec(ctx)
+	return nil
+}
+
+func (registrationexecutingtestcmd) Execute(context.Context, int) (NoResultType, error) {
+	return nil, nil
+}
+
+// completes returns true if the specified function returns within a second.
+func completes(fn func()) bool {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
 func TestRegistrationFunction(t *testing.T) {
 	// ARRANGE
 	ctx := context.Background()
@@ -158,3 +193,47 @@ func TestRegisterCommand(t *testing.T) {
 		}
 	})
 }
+
+// pointertestcmd is a command registered for a pointer request type.
+type pointertestcmd struct{}
+
+func (pointertestcmd) Execute(context.Context, *tenancytestrequest) (string, error) {
+	return "pointer", nil
+}
+
+func TestNearMisses(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	if err := RegisterCommand[*tenancytestrequest, string](ctx, pointertestcmd{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer delete(commands, reflect.TypeOf(&tenancytestrequest{}))
+
+	sr := reflect.TypeOf(strings.Reader{})
+	commands[sr] = nil
+	defer delete(commands, sr)
+
+	testcases := []struct {
+		name   string
+		rqt    reflect.Type
+		result []reflect.Type
+	}{
+		{name: "value for pointer registration", rqt: reflect.TypeOf(tenancytestrequest{}), result: []reflect.Type{reflect.TypeOf(&tenancytestrequest{})}},
+		{name: "same name in other package", rqt: reflect.TypeOf(&bytes.Reader{}), result: []reflect.Type{sr}},
+		{name: "no registration", rqt: reflect.TypeOf(0), result: []reflect.Type{}},
+		{name: "nil", rqt: nil, result: nil},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ACT
+			result := nearMisses(ctx, tc.rqt)
+
+			// ASSERT
+			wanted := tc.result
+			got := result
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package mediator
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Registry is a set of command registrations isolated from the global
+// registry.  When a request is executed using a context bound to a Registry
+// (see ContextWithRegistry), the command registered in the Registry for the
+// request type is called; if there is no command registered for the request
+// type in the Registry, any command registered in the global registry is
+// called.
+//
+// Registries isolate the mocks used by a test (see the mediatortest package)
+// so that tests mocking the same request type may be run in parallel.
+type Registry struct {
+	commands keyedRegistry
+}
+
+// NewRegistry returns a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// ContextWithRegistry returns a copy of the specified context bound to the
+// specified registry.
+func ContextWithRegistry(ctx context.Context, r *Registry) context.Context {
+	return context.WithValue(ctx, registryKey{}, r)
+}
+
+// RegisterCommandIn[TRequest, TResult] registers a command returning a specific
+// result type for the specified request type in the specified registry,
+// returning a function which removes the registration.
+//
+// The registration is subject to the same checks as RegisterCommand; any
+// OnRegister hooks are not called.
+func RegisterCommandIn[TRequest any, TResult any](ctx context.Context, r *Registry, cmd CommandHandler[TRequest, TResult]) (func(), error) {
+	return r.register(ctx, *new(TRequest), cmd)
+}
+
+// registryKey is the context key for a registry.
+type registryKey struct{}
+
+// register registers a command in the registry, returning a function which
+// removes the registration.  The registration is subject to the same checks
+// as registrations in the global registry.
+func (r *Registry) register(ctx context.Context, rq any, cmd any) (func(), error) {
+	return r.commands.register(ctx, commandKey{rqt: reflect.TypeOf(rq)}, rq, cmd)
+}
+
+// commandKey identifies a command registered for a request type, for a
+// specific tenant or with a name (if any).
+type commandKey struct {
+	rqt    reflect.Type
+	tenant string
+	name   string
+}
+
+// keyedRegistry holds commands registered with a commandKey.  The zero value
+// is an empty registry, ready to use.
+type keyedRegistry struct {
+	mu       sync.RWMutex
+	commands map[commandKey]any
+}
+
+// register registers a command for the specified request with the specified
+// key, returning a function which removes the registration.  The registration
+// is subject to the same checks as registrations in the global registry.
+//
+// The ConfigurationChecker of the command (if any) is called without holding
+// the lock of the registry, since it may execute requests which look up
+// commands in the registry; the key is checked again once locked, in case a
+// command was registered with the key in the meantime.
+func (kr *keyedRegistry) register(ctx context.Context, key commandKey, rq any, cmd any) (func(), error) {
+	if cmd, exists := kr.lookup(key); exists {
+		return nil, CommandAlreadyRegisteredError{command: cmd, request: rq}
+	}
+
+	// call the ConfigurationChecker, if implemented
+	if cfg, ok := cmd.(ConfigurationChecker); ok {
+		if err := cfg.CheckConfiguration(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if cmd, exists := kr.commands[key]; exists {
+		return nil, CommandAlreadyRegisteredError{command: cmd, request: rq}
+	}
+	if kr.commands == nil {
+		kr.commands = map[commandKey]any{}
+	}
+	kr.commands[key] = cmd
+
+	// the registration is removed only once, so that a function called again
+	// (e.g. when a test completes) does not remove any later registration
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			kr.mu.Lock()
+			defer kr.mu.Unlock()
+			delete(kr.commands, key)
+		})
+	}, nil
+}
+
+// lookup returns the command registered with the specified key, if any.
+func (kr *keyedRegistry) lookup(key commandKey) (any, bool) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	cmd, ok := kr.commands[key]
+	return cmd, ok
+}
+
+// empty returns true if no commands are registered.
+func (kr *keyedRegistry) empty() bool {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	return len(kr.commands) == 0
+}
+
+// each calls the specified function with the key of each registered command
+// and the command.
+func (kr *keyedRegistry) each(fn func(commandKey, any)) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	for key, cmd := range kr.commands {
+		fn(key, cmd)
+	}
+}
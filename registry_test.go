@@ -0,0 +1,264 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// testRegistries holds the registry of each test using testContext or the
+// test-scoped mock helpers below, which mirror those of the mediatortest
+// package (which cannot be used by the tests of this package).
+var testRegistries = struct {
+	sync.Mutex
+	m map[testing.TB]*Registry
+}{m: map[testing.TB]*Registry{}}
+
+// testRegistry returns the registry for the specified test, creating it if
+// necessary.
+func testRegistry(t testing.TB) *Registry {
+	testRegistries.Lock()
+	defer testRegistries.Unlock()
+
+	if r, ok := testRegistries.m[t]; ok {
+		return r
+	}
+
+	r := NewRegistry()
+	testRegistries.m[t] = r
+	t.Cleanup(func() {
+		testRegistries.Lock()
+		defer testRegistries.Unlock()
+
+		delete(testRegistries.m, t)
+	})
+	return r
+}
+
+// testContext returns a background context bound to the registry for the
+// specified test.
+func testContext(t testing.TB) context.Context {
+	return ContextWithRegistry(context.Background(), testRegistry(t))
+}
+
+// mockCommandFuncT registers a mock command in the registry for the specified
+// test, unregistering it when the test completes.
+func mockCommandFuncT[TRequest any, TResult any](t testing.TB, validate func(context.Context, TRequest) error, execute func(context.Context, TRequest) (TResult, error)) *Mock[TRequest, TResult] {
+	t.Helper()
+	mock, err := MockCommandFuncIn(testRegistry(t), validate, execute)
+	if err != nil {
+		t.Fatalf("unable to register mock: %v", err)
+	}
+	t.Cleanup(mock.Unregister)
+	return mock
+}
+
+// mockCommandT registers a mock command returning a zero-value result in the
+// registry for the specified test.
+func mockCommandT[TRequest any, TResult any](t testing.TB) *Mock[TRequest, TResult] {
+	t.Helper()
+	return mockCommandResultT[TRequest](t, *new(TResult))
+}
+
+// mockCommandResultT registers a mock command returning the specified result
+// in the registry for the specified test.
+func mockCommandResultT[TRequest any, TResult any](t testing.TB, result TResult) *Mock[TRequest, TResult] {
+	t.Helper()
+	return mockCommandFuncT[TRequest](t, nil, func(context.Context, TRequest) (TResult, error) { return result, nil })
+}
+
+// mockCommandErrorT registers a mock command returning the specified error in
+// the registry for the specified test.
+func mockCommandErrorT[TRequest any, TResult any](t testing.TB, err error) *Mock[TRequest, TResult] {
+	t.Helper()
+	return mockCommandFuncT[TRequest](t, nil, func(context.Context, TRequest) (TResult, error) { return *new(TResult), err })
+}
+
+// mockCommandValidationErrorT registers a mock command returning the
+// specified error from its validator in the registry for the specified test.
+func mockCommandValidationErrorT[TRequest any, TResult any](t testing.TB, err error) *Mock[TRequest, TResult] {
+	t.Helper()
+	return mockCommandFuncT[TRequest, TResult](t, func(context.Context, TRequest) error { return err }, nil)
+}
+
+func TestContextWithRegistry(t *testing.T) {
+	t.Run("registry mock is not registered in global registry", func(t *testing.T) {
+		// ARRANGE
+		_ = mockCommandT[string, NoResultType](t)
+
+		// ACT
+		_, err := Execute(context.Background(), "request", NoResult)
+
+		// ASSERT
+		if !errors.Is(err, &NoCommandForRequestTypeError{request: ""}) {
+			t.Errorf("wanted NoCommandForRequestTypeError, got %v", err)
+		}
+	})
+
+	t.Run("falls back to global registry", func(t *testing.T) {
+		// ARRANGE
+		ctx := ContextWithRegistry(context.Background(), NewRegistry())
+		mock := MockCommandResult[string](42)
+		defer mock.Unregister()
+
+		// ACT
+		result, err := Execute(ctx, "request", new(int))
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wanted := 42
+		got := result
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("registry mock is preferred over global registration", func(t *testing.T) {
+		// ARRANGE
+		r := NewRegistry()
+		ctx := ContextWithRegistry(context.Background(), r)
+		global := MockCommandResult[string](1)
+		defer global.Unregister()
+		local, _ := MockCommandFuncIn(r, nil, func(context.Context, string) (int, error) { return 2, nil })
+
+		// ACT
+		result, _ := Execute(ctx, "request", new(int))
+
+		// ASSERT
+		wanted := 2
+		got := result
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+		if global.WasCalled() || local.WasNotCalled() {
+			t.Error("wanted only registry mock to be called")
+		}
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	t.Run("RegisterCommandIn", func(t *testing.T) {
+		// ARRANGE
+		sut := NewRegistry()
+
+		// ACT
+		unreg, err := RegisterCommandIn[int, NoResultType](ctx, sut, registrationtestcmd{})
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := sut.commands.lookup(commandKey{rqt: reflect.TypeOf(0)}); !ok {
+			t.Error("command was not registered")
+		}
+
+		t.Run("unregister", func(t *testing.T) {
+			// ACT
+			unreg()
+
+			// ASSERT
+			if _, ok := sut.commands.lookup(commandKey{rqt: reflect.TypeOf(0)}); ok {
+				t.Error("command was not unregistered")
+			}
+		})
+
+		t.Run("unregister again", func(t *testing.T) {
+			// ARRANGE
+			_, _ = RegisterCommandIn[int, NoResultType](ctx, sut, registrationtestcmd{})
+
+			// ACT
+			unreg()
+
+			// ASSERT
+			if _, ok := sut.commands.lookup(commandKey{rqt: reflect.TypeOf(0)}); !ok {
+				t.Error("later registration was removed")
+			}
+		})
+	})
+
+	t.Run("when command already registered for request type", func(t *testing.T) {
+		// ARRANGE
+		sut := NewRegistry()
+		_, _ = sut.register(ctx, 0, registrationtestcmd{})
+
+		// ACT
+		fn, err := sut.register(ctx, 0, registrationtestcmd{})
+
+		// ASSERT
+		wanted := CommandAlreadyRegisteredError{command: registrationtestcmd{}, request: 0}
+		got := err
+		if fn != nil || wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("returns any ConfigurationChecker error", func(t *testing.T) {
+		// ARRANGE
+		sut := NewRegistry()
+		cfgerr := errors.New("configuration error")
+
+		// ACT
+		fn, err := sut.register(ctx, 0, registrationtestcmd{cfgerr})
+
+		// ASSERT
+		wanted := cfgerr
+		got := err
+		if fn != nil || wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when the ConfigurationChecker executes a request using the registry", func(t *testing.T) {
+		// ARRANGE
+		sut := NewRegistry()
+		ctx := ContextWithRegistry(ctx, sut)
+		cmd := registrationexecutingtestcmd{exec: func(ctx context.Context) { _, _ = Execute(ctx, "request", new(int)) }}
+
+		// ACT
+		ok := completes(func() { _, _ = RegisterCommandIn[int, NoResultType](ctx, sut, cmd) })
+
+		// ASSERT
+		if !ok {
+			t.Error("registration did not complete")
+		}
+	})
+}
+
+func TestMockCommandFuncIn(t *testing.T) {
+	t.Run("when command already registered for request type", func(t *testing.T) {
+		// ARRANGE
+		r := NewRegistry()
+		_, _ = MockCommandFuncIn[string, int](r, nil, nil)
+
+		// ACT
+		mock, err := MockCommandFuncIn[string, int](r, nil, nil)
+
+		// ASSERT
+		if mock != nil || !errors.As(err, new(CommandAlreadyRegisteredError)) {
+			t.Errorf("wanted CommandAlreadyRegisteredError, got %v", err)
+		}
+	})
+
+	t.Run("Unregister removes mock from registry", func(t *testing.T) {
+		// ARRANGE
+		r := NewRegistry()
+		ctx := ContextWithRegistry(context.Background(), r)
+		mock, _ := MockCommandFuncIn[string, NoResultType](r, nil, nil)
+
+		// ACT
+		mock.Unregister()
+
+		// ASSERT
+		_, err := Execute(ctx, "request", NoResult)
+		if !errors.Is(err, &NoCommandForRequestTypeError{request: ""}) {
+			t.Errorf("wanted NoCommandForRequestTypeError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,324 @@
+package mediator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrSagaNotFound is returned by a SagaStore when there is no saved state
+// for a saga instance.
+var ErrSagaNotFound = errors.New("saga not found")
+
+// SagaStatus identifies the status of a saga instance.
+type SagaStatus string
+
+const (
+	SagaRunning      SagaStatus = "running"      // steps are being executed
+	SagaCompensating SagaStatus = "compensating" // a step failed and completed steps are being compensated
+	SagaCompleted    SagaStatus = "completed"    // all steps completed successfully
+	SagaCompensated  SagaStatus = "compensated"  // a step failed and all completed steps were compensated
+	SagaFailed       SagaStatus = "failed"       // a step failed and a compensation also failed
+)
+
+// SagaState is the persisted state of a saga instance.
+type SagaState struct {
+	Saga        string          // the name of the saga
+	ID          string          // identifies the saga instance
+	Status      SagaStatus      // the status of the instance
+	Data        json.RawMessage // the saga data, encoded as JSON
+	Completed   []string        // the names of the steps completed, in order
+	FailedStep  string          // the name of the step that failed, if any
+	Error       string          // the error from the step that failed, if any
+	Compensated []string        // the names of the steps compensated, in order
+	UpdatedAt   time.Time       // the time at which the state was last saved
+}
+
+// SagaStore is the interface that must be implemented by a store for the
+// state of saga instances.
+type SagaStore interface {
+	// Save stores the state of a saga instance, replacing any state previously
+	// saved for the same saga and ID.
+	Save(ctx context.Context, state SagaState) error
+
+	// Load returns the state of the saga instance with the specified saga name
+	// and ID, or ErrSagaNotFound.
+	Load(ctx context.Context, saga string, id string) (SagaState, error)
+
+	// Incomplete returns the state of all instances of the named saga with a
+	// status of SagaRunning or SagaCompensating.
+	Incomplete(ctx context.Context, saga string) ([]SagaState, error)
+}
+
+// SagaAction[TData] is the signature of a function which performs (or
+// compensates) a step of a saga.  The function receives a pointer to the
+// data of the saga instance which it may read and modify.
+type SagaAction[TData any] func(ctx context.Context, data *TData) error
+
+// SagaCommand[TData, TRequest, TResult] returns a SagaAction which executes a
+// command via the mediator.  The request is obtained from the saga data by
+// the specified request function; if an apply function is specified, it is
+// called to update the saga data with the result of the command.
+//
+//	mediator.SagaCommand(
+//	    func(o *Order) chargeCard.Request { return chargeCard.Request{Amount: o.Total} },
+//	    func(o *Order, r *chargeCard.Result) { o.ChargeId = r.Id },
+//	)
+func SagaCommand[TData any, TRequest any, TResult any](request func(*TData) TRequest, apply func(*TData, TResult)) SagaAction[TData] {
+	return func(ctx context.Context, data *TData) error {
+		result, err := Execute(ctx, request(data), new(TResult))
+		if err != nil {
+			return err
+		}
+		if apply != nil {
+			apply(data, result)
+		}
+		return nil
+	}
+}
+
+// sagaStep holds the definition of a step in a saga.
+type sagaStep[TData any] struct {
+	name       string
+	action     SagaAction[TData]
+	compensate SagaAction[TData]
+}
+
+// Saga[TData] defines a sequence of steps, each with an optional compensating
+// action which undoes the step.  If a step fails, the compensating actions of
+// all completed steps are performed in reverse order.
+//
+// The state of each saga instance is saved to a SagaStore when it is started
+// and after every step, enabling incomplete instances to be resumed (e.g. after a restart).
+type Saga[TData any] struct {
+	name  string
+	store SagaStore
+	steps []sagaStep[TData]
+}
+
+// NewSaga[TData] returns a new saga with the specified name, persisting the
+// state of instances in the specified store.  The name identifies the saga in
+// the store so must be unique.
+//
+// If the store is nil the state of instances is not persisted and instances
+// cannot be resumed.
+func NewSaga[TData any](name string, store SagaStore) *Saga[TData] {
+	return &Saga[TData]{name: name, store: store}
+}
+
+// Step adds a step to the saga with the specified name, action and compensating
+// action, returning the saga to allow steps to be chained.  The compensating
+// action may be nil if the step does not require compensation.
+//
+// Step names identify completed steps when an instance is resumed, so must be
+// unique within a saga; the function panics if a step with the same name has
+// already been added.
+func (saga *Saga[TData]) Step(name string, action SagaAction[TData], compensate SagaAction[TData]) *Saga[TData] {
+	for _, step := range saga.steps {
+		if step.name == name {
+			panic(fmt.Sprintf("saga %q already has a step named %q", saga.name, name))
+		}
+	}
+	saga.steps = append(saga.steps, sagaStep[TData]{name: name, action: action, compensate: compensate})
+	return saga
+}
+
+// Run starts a new instance of the saga with the specified ID and data,
+// returning the data as modified by the steps performed.
+//
+// The state of the instance is saved before the first step is performed.
+//
+// If a step fails, completed steps are compensated and a SagaError returned.
+// If the store returns an error, execution of the saga stops and the error
+// is returned; the instance may be resumed once the store is available.
+func (saga *Saga[TData]) Run(ctx context.Context, id string, data TData) (TData, error) {
+	state := SagaState{Saga: saga.name, ID: id, Status: SagaRunning}
+	if err := saga.save(ctx, &state, data); err != nil {
+		return data, err
+	}
+	return saga.run(ctx, state, data)
+}
+
+// Resume resumes the saga instance with the specified ID, continuing to
+// perform (or compensate) steps from the point at which the instance stopped.
+//
+// If the instance has already completed, the data of the instance is returned,
+// with a SagaError if the instance did not complete successfully.
+func (saga *Saga[TData]) Resume(ctx context.Context, id string) (TData, error) {
+	z := *new(TData)
+	if saga.store == nil {
+		return z, fmt.Errorf("saga %q: cannot resume without a store", saga.name)
+	}
+
+	state, err := saga.store.Load(ctx, saga.name, id)
+	if err != nil {
+		return z, err
+	}
+
+	data := new(TData)
+	if err := json.Unmarshal(state.Data, data); err != nil {
+		return z, err
+	}
+	return saga.run(ctx, state, *data)
+}
+
+// ResumeAll resumes all incomplete instances of the saga in the store.  Every
+// instance is resumed, regardless of errors; the first error (if any) is
+// returned.
+func (saga *Saga[TData]) ResumeAll(ctx context.Context) error {
+	if saga.store == nil {
+		return fmt.Errorf("saga %q: cannot resume without a store", saga.name)
+	}
+
+	states, err := saga.store.Incomplete(ctx, saga.name)
+	if err != nil {
+		return err
+	}
+
+	var result error
+	for _, state := range states {
+		if _, err := saga.Resume(ctx, state.ID); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// run performs (or compensates) the steps of a saga instance, starting from
+// the specified state.
+func (saga *Saga[TData]) run(ctx context.Context, state SagaState, data TData) (TData, error) {
+	if len(state.Completed) > len(saga.steps) {
+		return data, fmt.Errorf("saga %q (%s): state has more completed steps than defined", saga.name, state.ID)
+	}
+	for i, name := range state.Completed {
+		if saga.steps[i].name != name {
+			return data, fmt.Errorf("saga %q (%s): completed step %q does not match step %q", saga.name, state.ID, name, saga.steps[i].name)
+		}
+	}
+
+	if state.Status == SagaRunning {
+		for _, step := range saga.steps[len(state.Completed):] {
+			if err := step.action(ctx, &data); err != nil {
+				state.Status = SagaCompensating
+				state.FailedStep = step.name
+				state.Error = err.Error()
+				if err := saga.save(ctx, &state, data); err != nil {
+					return data, err
+				}
+				return data, saga.compensate(ctx, &state, &data, err)
+			}
+			state.Completed = append(state.Completed, step.name)
+			if err := saga.save(ctx, &state, data); err != nil {
+				return data, err
+			}
+		}
+		state.Status = SagaCompleted
+		return data, saga.save(ctx, &state, data)
+	}
+
+	switch state.Status {
+	case SagaCompensating:
+		return data, saga.compensate(ctx, &state, &data, errors.New(state.Error))
+	case SagaCompensated, SagaFailed:
+		return data, saga.error(state, errors.New(state.Error), "", nil)
+	}
+	return data, nil
+}
+
+// compensate performs the compensating actions of completed steps, in reverse
+// order, skipping any steps already compensated.  A SagaError is returned
+// identifying the failed step, the compensated steps and any compensation that
+// failed.
+func (saga *Saga[TData]) compensate(ctx context.Context, state *SagaState, data *TData, cause error) error {
+	compensated := map[string]bool{}
+	for _, name := range state.Compensated {
+		compensated[name] = true
+	}
+
+	for i := len(state.Completed) - 1; i >= 0; i-- {
+		step := saga.steps[i]
+		if compensated[step.name] {
+			continue
+		}
+		if step.compensate != nil {
+			if err := step.compensate(ctx, data); err != nil {
+				state.Status = SagaFailed
+				if serr := saga.save(ctx, state, *data); serr != nil {
+					return serr
+				}
+				return saga.error(*state, cause, step.name, err)
+			}
+		}
+		state.Compensated = append(state.Compensated, step.name)
+		if err := saga.save(ctx, state, *data); err != nil {
+			return err
+		}
+	}
+
+	state.Status = SagaCompensated
+	if err := saga.save(ctx, state, *data); err != nil {
+		return err
+	}
+	return saga.error(*state, cause, "", nil)
+}
+
+// save saves the state of a saga instance, with the specified data, to the
+// store (if any).
+func (saga *Saga[TData]) save(ctx context.Context, state *SagaState, data TData) error {
+	if saga.store == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	state.Data = b
+	state.UpdatedAt = now()
+
+	return saga.store.Save(ctx, *state)
+}
+
+// error returns a SagaError for the specified state.
+func (saga *Saga[TData]) error(state SagaState, cause error, step string, err error) SagaError {
+	return SagaError{
+		Saga:             state.Saga,
+		ID:               state.ID,
+		Step:             state.FailedStep,
+		E:                cause,
+		Compensated:      append([]string{}, state.Compensated...),
+		CompensationStep: step,
+		CompensationErr:  err,
+	}
+}
+
+// SagaError is returned when a step of a saga fails.  It identifies the step
+// that failed (wrapping the error from that step), the steps that were
+// compensated and any compensating action that failed.
+//
+//	saga "<saga>" (<id>) failed at step "<step>": <error>; compensated: [<steps>]
+//	saga "<saga>" (<id>) failed at step "<step>": <error>; compensated: [<steps>]; compensation of "<step>" failed: <error>
+type SagaError struct {
+	Saga             string   // the name of the saga
+	ID               string   // identifies the saga instance
+	Step             string   // the name of the step that failed
+	E                error    // the error returned by the step that failed
+	Compensated      []string // the names of the steps compensated, in the order compensated
+	CompensationStep string   // the name of the step for which compensation failed, if any
+	CompensationErr  error    // the error returned by the compensation that failed, if any
+}
+
+func (e SagaError) Error() string {
+	s := fmt.Sprintf("saga %q (%s) failed at step %q: %v; compensated: [%s]", e.Saga, e.ID, e.Step, e.E, strings.Join(e.Compensated, ", "))
+	if e.CompensationErr != nil {
+		s += fmt.Sprintf("; compensation of %q failed: %v", e.CompensationStep, e.CompensationErr)
+	}
+	return s
+}
+
+func (e SagaError) Unwrap() error {
+	return e.E
+}
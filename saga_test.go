@@ -0,0 +1,439 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// sagateststore is an in-memory SagaStore used for testing sagas.
+type sagateststore struct {
+	states  map[string]SagaState
+	saveerr error
+}
+
+func (s *sagateststore) Save(_ context.Context, state SagaState) error {
+	if s.saveerr != nil {
+		return s.saveerr
+	}
+	if s.states == nil {
+		s.states = map[string]SagaState{}
+	}
+	s.states[state.Saga+"/"+state.ID] = state
+	return nil
+}
+
+func (s *sagateststore) Load(_ context.Context, saga string, id string) (SagaState, error) {
+	state, ok := s.states[saga+"/"+id]
+	if !ok {
+		return SagaState{}, ErrSagaNotFound
+	}
+	return state, nil
+}
+
+func (s *sagateststore) Incomplete(_ context.Context, saga string) ([]SagaState, error) {
+	result := []SagaState{}
+	for _, state := range s.states {
+		if state.Saga == saga && (state.Status == SagaRunning || state.Status == SagaCompensating) {
+			result = append(result, state)
+		}
+	}
+	return result, nil
+}
+
+// sagatestdata is the data of sagas used in tests.
+type sagatestdata struct {
+	Log []string
+}
+
+// sagatestaction returns a SagaAction which appends the specified entry to the
+// log in the saga data, returning the specified error.
+func sagatestaction(entry string, err error) SagaAction[sagatestdata] {
+	return func(_ context.Context, data *sagatestdata) error {
+		data.Log = append(data.Log, entry)
+		return err
+	}
+}
+
+func TestSaga(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	steperr := errors.New("step failed")
+
+	t.Run("when all steps succeed", func(t *testing.T) {
+		// ARRANGE
+		store := &sagateststore{}
+		sut := NewSaga[sagatestdata]("test", store).
+			Step("a", sagatestaction("a", nil), sagatestaction("undo a", nil)).
+			Step("b", sagatestaction("b", nil), sagatestaction("undo b", nil))
+
+		// ACT
+		data, err := sut.Run(ctx, "1", sagatestdata{})
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		t.Run("performs all steps", func(t *testing.T) {
+			wanted := []string{"a", "b"}
+			got := data.Log
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("saves completed state", func(t *testing.T) {
+			state := store.states["test/1"]
+			if state.Status != SagaCompleted || !reflect.DeepEqual(state.Completed, []string{"a", "b"}) {
+				t.Errorf("wanted completed with steps [a b], got %#v", state)
+			}
+		})
+	})
+
+	t.Run("when a step fails", func(t *testing.T) {
+		// ARRANGE
+		store := &sagateststore{}
+		sut := NewSaga[sagatestdata]("test", store).
+			Step("a", sagatestaction("a", nil), sagatestaction("undo a", nil)).
+			Step("b", sagatestaction("b", nil), nil).
+			Step("c", sagatestaction("c", steperr), sagatestaction("undo c", nil))
+
+		// ACT
+		data, err := sut.Run(ctx, "1", sagatestdata{})
+
+		// ASSERT
+		t.Run("compensates completed steps in reverse order", func(t *testing.T) {
+			wanted := []string{"a", "b", "c", "undo a"}
+			got := data.Log
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("returns SagaError", func(t *testing.T) {
+			wanted := SagaError{Saga: "test", ID: "1", Step: "c", E: steperr, Compensated: []string{"b", "a"}}
+			got := err
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+			if !errors.Is(err, steperr) {
+				t.Error("SagaError does not wrap step error")
+			}
+		})
+
+		t.Run("saves compensated state", func(t *testing.T) {
+			wanted := SagaCompensated
+			got := store.states["test/1"].Status
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("when a compensation fails", func(t *testing.T) {
+		// ARRANGE
+		comperr := errors.New("compensation failed")
+		store := &sagateststore{}
+		sut := NewSaga[sagatestdata]("test", store).
+			Step("a", sagatestaction("a", nil), sagatestaction("undo a", nil)).
+			Step("b", sagatestaction("b", nil), sagatestaction("undo b", comperr)).
+			Step("c", sagatestaction("c", steperr), nil)
+
+		// ACT
+		_, err := sut.Run(ctx, "1", sagatestdata{})
+
+		// ASSERT
+		t.Run("returns SagaError", func(t *testing.T) {
+			wanted := SagaError{Saga: "test", ID: "1", Step: "c", E: steperr, Compensated: []string{}, CompensationStep: "b", CompensationErr: comperr}
+			got := err
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("saves failed state", func(t *testing.T) {
+			wanted := SagaFailed
+			got := store.states["test/1"].Status
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("when the store fails", func(t *testing.T) {
+		// ARRANGE
+		store := &sagateststore{saveerr: errors.New("store failed")}
+		sut := NewSaga[sagatestdata]("test", store).
+			Step("a", sagatestaction("a", nil), nil).
+			Step("b", sagatestaction("b", nil), nil)
+
+		// ACT
+		data, err := sut.Run(ctx, "1", sagatestdata{})
+
+		// ASSERT
+		if err != store.saveerr {
+			t.Errorf("\nwanted %#v\ngot    %#v", store.saveerr, err)
+		}
+		if len(data.Log) != 0 {
+			t.Errorf("wanted no steps performed, got %v", data.Log)
+		}
+	})
+
+	t.Run("when the first step is running", func(t *testing.T) {
+		// ARRANGE
+		store := &sagateststore{}
+		var got SagaState
+		sut := NewSaga[sagatestdata]("test", store).
+			Step("a", func(ctx context.Context, data *sagatestdata) error {
+				got = store.states["test/1"]
+				return nil
+			}, nil)
+
+		// ACT
+		_, err := sut.Run(ctx, "1", sagatestdata{Log: []string{"initial"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		t.Run("has saved running state", func(t *testing.T) {
+			wanted := SagaState{Saga: "test", ID: "1", Status: SagaRunning}
+			if got.Saga != wanted.Saga || got.ID != wanted.ID || got.Status != wanted.Status || len(got.Completed) != 0 {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("has saved initial data", func(t *testing.T) {
+			wanted := `{"Log":["initial"]}`
+			got := string(got.Data)
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("when adding a duplicate step", func(t *testing.T) {
+		defer func() { // panic tests must be deferred
+			if r := recover(); r == nil {
+				t.Errorf("did not panic")
+			}
+		}()
+
+		NewSaga[sagatestdata]("test", nil).
+			Step("a", sagatestaction("a", nil), nil).
+			Step("a", sagatestaction("a", nil), nil)
+	})
+}
+
+func TestSagaResume(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	saga := func(store SagaStore) *Saga[sagatestdata] {
+		return NewSaga[sagatestdata]("test", store).
+			Step("a", sagatestaction("a", nil), sagatestaction("undo a", nil)).
+			Step("b", sagatestaction("b", nil), sagatestaction("undo b", nil)).
+			Step("c", sagatestaction("c", nil), sagatestaction("undo c", nil))
+	}
+
+	t.Run("when running", func(t *testing.T) {
+		// ARRANGE
+		store := &sagateststore{}
+		_ = store.Save(ctx, SagaState{Saga: "test", ID: "1", Status: SagaRunning, Data: []byte(`{"Log":["a"]}`), Completed: []string{"a"}})
+
+		// ACT
+		data, err := saga(store).Resume(ctx, "1")
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wanted := []string{"a", "b", "c"}
+		got := data.Log
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when compensating", func(t *testing.T) {
+		// ARRANGE
+		store := &sagateststore{}
+		_ = store.Save(ctx, SagaState{
+			Saga: "test", ID: "1", Status: SagaCompensating, Data: []byte(`{"Log":["a","b","c","undo b"]}`),
+			Completed: []string{"a", "b"}, FailedStep: "c", Error: "step failed", Compensated: []string{"b"},
+		})
+
+		// ACT
+		data, err := saga(store).Resume(ctx, "1")
+
+		// ASSERT
+		t.Run("compensates remaining steps", func(t *testing.T) {
+			wanted := []string{"a", "b", "c", "undo b", "undo a"}
+			got := data.Log
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("returns SagaError", func(t *testing.T) {
+			serr := SagaError{}
+			if !errors.As(err, &serr) || serr.Step != "c" || !reflect.DeepEqual(serr.Compensated, []string{"b", "a"}) {
+				t.Errorf("unexpected error: %#v", err)
+			}
+		})
+	})
+
+	t.Run("when state does not match definition", func(t *testing.T) {
+		// ARRANGE
+		store := &sagateststore{}
+		_ = store.Save(ctx, SagaState{Saga: "test", ID: "1", Status: SagaRunning, Data: []byte(`{}`), Completed: []string{"x"}})
+
+		// ACT
+		_, err := saga(store).Resume(ctx, "1")
+
+		// ASSERT
+		if err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+
+	t.Run("when not found", func(t *testing.T) {
+		// ACT
+		_, err := saga(&sagateststore{}).Resume(ctx, "1")
+
+		// ASSERT
+		wanted := ErrSagaNotFound
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("without a store", func(t *testing.T) {
+		// ACT
+		_, err := saga(nil).Resume(ctx, "1")
+
+		// ASSERT
+		if err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+
+	t.Run("ResumeAll", func(t *testing.T) {
+		// ARRANGE
+		store := &sagateststore{}
+		_ = store.Save(ctx, SagaState{Saga: "test", ID: "1", Status: SagaRunning, Data: []byte(`{}`)})
+		_ = store.Save(ctx, SagaState{Saga: "test", ID: "2", Status: SagaRunning, Data: []byte(`{}`), Completed: []string{"a"}})
+		_ = store.Save(ctx, SagaState{Saga: "other", ID: "3", Status: SagaRunning, Data: []byte(`{}`)})
+
+		// ACT
+		err := saga(store).ResumeAll(ctx)
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, key := range []string{"test/1", "test/2"} {
+			if store.states[key].Status != SagaCompleted {
+				t.Errorf("%s: wanted %s, got %s", key, SagaCompleted, store.states[key].Status)
+			}
+		}
+		if store.states["other/3"].Status != SagaRunning {
+			t.Error("resumed instance of other saga")
+		}
+	})
+}
+
+func TestSagaCommand(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	type data struct {
+		Request string
+		Result  int
+	}
+
+	mock := MockCommandResult[string](42)
+	defer mock.Unregister()
+
+	sut := SagaCommand(
+		func(d *data) string { return d.Request },
+		func(d *data, r int) { d.Result = r },
+	)
+
+	// ACT
+	d := &data{Request: "request"}
+	err := sut(ctx, d)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("executes command with request from data", func(t *testing.T) {
+		wanted := []string{"request"}
+		got := mock.Requests()
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("applies result to data", func(t *testing.T) {
+		wanted := 42
+		got := d.Result
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("returns command error", func(t *testing.T) {
+		// ARRANGE
+		mock.Unregister()
+		cmderr := errors.New("command error")
+		mock := MockCommandError[string, int](cmderr)
+		defer mock.Unregister()
+
+		// ACT
+		err := sut(ctx, d)
+
+		// ASSERT
+		wanted := cmderr
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestSagaError(t *testing.T) {
+	testcases := []struct {
+		name   string
+		sut    SagaError
+		result string
+	}{
+		{
+			name:   "compensated",
+			sut:    SagaError{Saga: "order", ID: "1", Step: "charge", E: errors.New("declined"), Compensated: []string{"reserve", "create"}},
+			result: `saga "order" (1) failed at step "charge": declined; compensated: [reserve, create]`,
+		},
+		{
+			name:   "compensation failed",
+			sut:    SagaError{Saga: "order", ID: "1", Step: "charge", E: errors.New("declined"), CompensationStep: "reserve", CompensationErr: errors.New("unavailable")},
+			result: `saga "order" (1) failed at step "charge": declined; compensated: []; compensation of "reserve" failed: unavailable`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ACT
+			got := tc.sut.Error()
+
+			// ASSERT
+			wanted := tc.result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package mediator
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RegistrationInfo describes a command registered with the mediator.
+type RegistrationInfo struct {
+	RequestType         string `json:"requestType"`
+	Tenant              string `json:"tenant,omitempty"`
+	Name                string `json:"name,omitempty"`
+	Interface           bool   `json:"interface,omitempty"`
+	Priority            int    `json:"priority,omitempty"`
+	ResultType          string `json:"resultType"`
+	HandlerType         string `json:"handlerType"`
+	Validates           bool   `json:"validates"`
+	ChecksConfiguration bool   `json:"checksConfiguration"`
+}
+
+// CommandStats records the executions of requests of a specific type.
+type CommandStats struct {
+	RequestType string              `json:"requestType"`
+	Calls       uint64              `json:"calls"`
+	Errors      uint64              `json:"errors"`
+	ErrorRate   float64             `json:"errorRate"`
+	Latency     LatencyStats        `json:"latency"`
+	InFlight    []InFlightExecution `json:"inFlight,omitempty"`
+}
+
+// LatencyStats summarises the duration of the most recent executions of
+// requests of a specific type.  The number of executions sampled is limited
+// (see StatsSampleSize).
+type LatencyStats struct {
+	Samples int           `json:"samples"`
+	P50     time.Duration `json:"p50"`
+	P90     time.Duration `json:"p90"`
+	P99     time.Duration `json:"p99"`
+	Max     time.Duration `json:"max"`
+}
+
+// InFlightExecution identifies a request that is currently being executed.
+type InFlightExecution struct {
+	Started time.Time     `json:"started"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// StatsSampleSize is the number of the most recent executions of each request
+// type from which latency percentiles are determined.
+const StatsSampleSize = 1024
+
+// commandStats accumulates the statistics for a request type.
+type commandStats struct {
+	mu        sync.Mutex
+	calls     uint64
+	errors    uint64
+	latencies [StatsSampleSize]time.Duration // a ring buffer of sampled durations
+	inflight  map[uint64]time.Time
+}
+
+// stats holds the statistics for each request type executed.
+var stats = struct {
+	sync.RWMutex
+	commands map[reflect.Type]*commandStats
+}{commands: map[reflect.Type]*commandStats{}}
+
+// statsSeq provides identifiers for in-flight executions.
+var statsSeq uint64
+
+// track records the start of the execution of a request of the specified type,
+// returning a function to be called with the outcome when execution is
+// complete.
+func track(rqt reflect.Type) func(error) {
+	stats.RLock()
+	cs, ok := stats.commands[rqt]
+	stats.RUnlock()
+	if !ok {
+		stats.Lock()
+		if cs, ok = stats.commands[rqt]; !ok {
+			cs = &commandStats{inflight: map[uint64]time.Time{}}
+			stats.commands[rqt] = cs
+		}
+		stats.Unlock()
+	}
+
+	id := atomic.AddUint64(&statsSeq, 1)
+	start := time.Now()
+
+	cs.mu.Lock()
+	cs.inflight[id] = start
+	cs.mu.Unlock()
+
+	return func(err error) {
+		d := time.Since(start)
+
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		delete(cs.inflight, id)
+		cs.latencies[cs.calls%StatsSampleSize] = d
+		cs.calls++
+		if err != nil {
+			cs.errors++
+		}
+	}
+}
+
+// Registrations returns a description of each command registered with the
+// mediator, sorted by request type.  Commands registered for a specific tenant
+// (see RegisterTenantCommand) are listed following the command registered for
+// all tenants, sorted by tenant.  Commands registered in a Registry (see
+// ContextWithRegistry) are not included.  Named commands (see
+// RegisterNamedCommand) are listed following any tenant-specific commands,
+// sorted by name.  Commands registered for an interface (see
+// RegisterInterfaceCommand) are listed by interface type, with the priority.
+func Registrations() []RegistrationInfo {
+	result := make([]RegistrationInfo, 0, len(commands))
+	for rqt, cmd := range commands {
+		result = append(result, registrationInfo(rqt, cmd))
+	}
+
+	tenantCommands.each(func(key commandKey, cmd any) {
+		info := registrationInfo(key.rqt, cmd)
+		info.Tenant = key.tenant
+		result = append(result, info)
+	})
+
+	namedCommands.each(func(key commandKey, cmd any) {
+		info := registrationInfo(key.rqt, cmd)
+		info.Name = key.name
+		result = append(result, info)
+	})
+
+	interfaceCommands.RLock()
+	for _, reg := range interfaceCommands.regs {
+		result = append(result, interfaceRegistrationInfo(reg))
+	}
+	interfaceCommands.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch {
+		case a.RequestType != b.RequestType:
+			return a.RequestType < b.RequestType
+		case a.Name != b.Name:
+			return a.Name < b.Name
+		}
+		return a.Tenant < b.Tenant
+	})
+	return result
+}
+
+// registrationInfo returns a description of a command registered for a
+// request type.
+func registrationInfo(rqt reflect.Type, cmd any) RegistrationInfo {
+	handler := unwrap(cmd)
+	info := RegistrationInfo{
+		RequestType: typeName(rqt),
+		HandlerType: typeName(reflect.TypeOf(handler)),
+	}
+	if m, ok := reflect.TypeOf(cmd).MethodByName("Execute"); ok && m.Type.NumOut() > 0 {
+		info.ResultType = typeName(m.Type.Out(0))
+	}
+	if m, ok := reflect.TypeOf(handler).MethodByName("Validate"); ok && m.Type.NumIn() == 3 {
+		info.Validates = m.Type.In(2) == rqt
+	}
+	_, info.ChecksConfiguration = handler.(ConfigurationChecker)
+	return info
+}
+
+// Stats returns the statistics for each request type executed by the mediator,
+// sorted by request type.  In-flight executions are sorted by the time at
+// which they started.
+//
+// An execution in which the command panics is recorded as an error.
+func Stats() []CommandStats {
+	stats.RLock()
+	defer stats.RUnlock()
+
+	t := time.Now()
+	result := make([]CommandStats, 0, len(stats.commands))
+	for rqt, cs := range stats.commands {
+		result = append(result, cs.snapshot(typeName(rqt), t))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RequestType < result[j].RequestType })
+	return result
+}
+
+// snapshot returns the statistics for a request type at the specified time.
+func (cs *commandStats) snapshot(rqt string, t time.Time) CommandStats {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	result := CommandStats{
+		RequestType: rqt,
+		Calls:       cs.calls,
+		Errors:      cs.errors,
+	}
+	if cs.calls > 0 {
+		result.ErrorRate = float64(cs.errors) / float64(cs.calls)
+	}
+
+	n := int(cs.calls)
+	if cs.calls > StatsSampleSize {
+		n = StatsSampleSize
+	}
+	samples := make([]time.Duration, n)
+	copy(samples, cs.latencies[:n])
+	result.Latency = latencyStats(samples)
+
+	for _, start := range cs.inflight {
+		result.InFlight = append(result.InFlight, InFlightExecution{Started: start, Elapsed: t.Sub(start)})
+	}
+	sort.Slice(result.InFlight, func(i, j int) bool { return result.InFlight[i].Started.Before(result.InFlight[j].Started) })
+
+	return result
+}
+
+// latencyStats returns the percentiles of the specified durations, using the
+// nearest-rank method.  The durations are sorted in place.
+func latencyStats(samples []time.Duration) LatencyStats {
+	n := len(samples)
+	if n == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	percentile := func(p int) time.Duration {
+		rank := (p*n + 99) / 100
+		return samples[rank-1]
+	}
+	return LatencyStats{
+		Samples: n,
+		P50:     percentile(50),
+		P90:     percentile(90),
+		P99:     percentile(99),
+		Max:     samples[n-1],
+	}
+}
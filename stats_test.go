@@ -0,0 +1,230 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// statstestrequest is a request type used for testing statistics.
+type statstestrequest struct{ fail bool }
+
+// statstestcmd is a command used for testing registrations.
+type statstestcmd struct{}
+
+func (statstestcmd) CheckConfiguration(context.Context) error               { return nil }
+func (statstestcmd) Validate(context.Context, statstestrequest) error       { return nil }
+func (statstestcmd) Execute(context.Context, statstestrequest) (int, error) { return 0, nil }
+
+// resetStats replaces the statistics collected by the mediator for the
+// duration of a test.
+func resetStats(t *testing.T) {
+	og := stats.commands
+	stats.commands = map[reflect.Type]*commandStats{}
+	t.Cleanup(func() { stats.commands = og })
+}
+
+func TestRegistrations(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	if err := RegisterCommand[statstestrequest, int](ctx, statstestcmd{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer delete(commands, reflect.TypeOf(statstestrequest{}))
+
+	mock := MockCommand[int, string]()
+	defer mock.Unregister()
+
+	// ACT
+	result := Registrations()
+
+	// ASSERT
+	wanted := []RegistrationInfo{
+		{
+			RequestType:         "github.com/blugnu/mediator.statstestrequest",
+			ResultType:          "int",
+			HandlerType:         "github.com/blugnu/mediator.statstestcmd",
+			Validates:           true,
+			ChecksConfiguration: true,
+		},
+		{
+			RequestType: "int",
+			ResultType:  "string",
+			HandlerType: "*github.com/blugnu/mediator.Mock[int,string]",
+			Validates:   true,
+		},
+	}
+	got := result
+	if !reflect.DeepEqual(wanted, got) {
+		t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	t.Run("records calls and errors", func(t *testing.T) {
+		// ARRANGE
+		resetStats(t)
+		ctx := testContext(t)
+		_ = mockCommandFuncT(t, nil, func(_ context.Context, rq statstestrequest) (int, error) {
+			if rq.fail {
+				return 0, errors.New("failed")
+			}
+			return 1, nil
+		})
+
+		// ACT
+		_, _ = Execute(ctx, statstestrequest{}, new(int))
+		_, _ = Execute(ctx, statstestrequest{}, new(int))
+		_, _ = Execute(ctx, statstestrequest{}, new(int))
+		_, _ = Execute(ctx, statstestrequest{fail: true}, new(int))
+		_, _ = Execute(ctx, "no command", NoResult)
+
+		// ASSERT
+		result := Stats()
+		if len(result) != 2 {
+			t.Fatalf("\nwanted 2 request types\ngot    %d", len(result))
+		}
+
+		t.Run("for request with a command", func(t *testing.T) {
+			s := result[0]
+			wanted := []any{"github.com/blugnu/mediator.statstestrequest", uint64(4), uint64(1), 0.25, 4, 0}
+			got := []any{s.RequestType, s.Calls, s.Errors, s.ErrorRate, s.Latency.Samples, len(s.InFlight)}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("for request with no command", func(t *testing.T) {
+			s := result[1]
+			wanted := []any{"string", uint64(1), uint64(1), 1.0}
+			got := []any{s.RequestType, s.Calls, s.Errors, s.ErrorRate}
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("records in-flight executions", func(t *testing.T) {
+		// ARRANGE
+		resetStats(t)
+		ctx := testContext(t)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		_ = mockCommandFuncT(t, nil, func(context.Context, statstestrequest) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = Execute(ctx, statstestrequest{}, new(int))
+		}()
+		<-started
+
+		// ACT
+		inflight := Stats()[0].InFlight
+		close(release)
+		<-done
+		completed := Stats()[0].InFlight
+
+		// ASSERT
+		t.Run("while executing", func(t *testing.T) {
+			wanted := 1
+			got := len(inflight)
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("when completed", func(t *testing.T) {
+			wanted := 0
+			got := len(completed)
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("records a panic as an error", func(t *testing.T) {
+		// ARRANGE
+		resetStats(t)
+		ctx := testContext(t)
+		_ = mockCommandFuncT(t, nil, func(context.Context, statstestrequest) (int, error) {
+			panic("command panicked")
+		})
+
+		// ACT
+		func() {
+			defer func() { _ = recover() }()
+			_, _ = Execute(ctx, statstestrequest{}, new(int))
+		}()
+
+		// ASSERT
+		s := Stats()[0]
+		wanted := []any{uint64(1), uint64(1), 0}
+		got := []any{s.Calls, s.Errors, len(s.InFlight)}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("samples the most recent executions", func(t *testing.T) {
+		// ARRANGE
+		resetStats(t)
+		ctx := testContext(t)
+		_ = mockCommandT[statstestrequest, int](t)
+
+		// ACT
+		for i := 0; i < StatsSampleSize+10; i++ {
+			_, _ = Execute(ctx, statstestrequest{}, new(int))
+		}
+
+		// ASSERT
+		s := Stats()[0]
+		wanted := []any{uint64(StatsSampleSize + 10), StatsSampleSize}
+		got := []any{s.Calls, s.Latency.Samples}
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestLatencyStats(t *testing.T) {
+	// ARRANGE
+	ms := time.Millisecond
+	samples := func(n int) []time.Duration {
+		result := make([]time.Duration, n)
+		for i := range result {
+			result[i] = time.Duration(n-i) * ms
+		}
+		return result
+	}
+
+	testcases := []struct {
+		scenario string
+		samples  []time.Duration
+		result   LatencyStats
+	}{
+		{scenario: "no samples", samples: nil, result: LatencyStats{}},
+		{scenario: "one sample", samples: []time.Duration{ms}, result: LatencyStats{Samples: 1, P50: ms, P90: ms, P99: ms, Max: ms}},
+		{scenario: "100 samples", samples: samples(100), result: LatencyStats{Samples: 100, P50: 50 * ms, P90: 90 * ms, P99: 99 * ms, Max: 100 * ms}},
+		{scenario: "10 samples", samples: samples(10), result: LatencyStats{Samples: 10, P50: 5 * ms, P90: 9 * ms, P99: 10 * ms, Max: 10 * ms}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ACT
+			result := latencyStats(tc.samples)
+
+			// ASSERT
+			wanted := tc.result
+			got := result
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	}
+}
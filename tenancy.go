@@ -0,0 +1,98 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// ErrNoTenant is returned when registering a command for a tenant if no
+// tenant is specified.
+var ErrNoTenant = errors.New("no tenant")
+
+// tenantCommands holds the commands registered for specific tenants, keyed by
+// tenant and request type.
+var tenantCommands keyedRegistry
+
+// tenant provides the function used to identify the tenant for which a
+// request is executed (see SetTenantFunc).
+var tenant = defaultTenant
+
+// defaultTenant returns the TenantID of the metadata associated with the
+// context.
+func defaultTenant(ctx context.Context) string {
+	return RequestMetadata(ctx).TenantID
+}
+
+// SetTenantFunc establishes a function used to identify the tenant for which
+// a request is executed, enabling the mediator to use any existing mechanism
+// for identifying tenants.
+//
+// By default the tenant is the TenantID of the Metadata of the request (see
+// RequestMetadata).  Specifying a nil function restores the default.
+func SetTenantFunc(fn func(context.Context) string) {
+	if fn == nil {
+		fn = defaultTenant
+	}
+	tenant = fn
+}
+
+// Tenant returns the tenant for which requests executed using the specified
+// context are executed, as identified by the current tenant function.  If
+// there is no tenant, an empty string is returned.
+func Tenant(ctx context.Context) string {
+	return tenant(ctx)
+}
+
+// RegisterTenantCommand[TRequest, TResult] registers a command returning a
+// specific result type for the specified request type, executed only for
+// requests executed for the specified tenant (see SetTenantFunc).  This
+// overrides any command registered using RegisterCommand for the request type,
+// for that tenant.  Requests for other tenants (or with no tenant) are
+// executed by the command registered using RegisterCommand.
+//
+// The command must return the same result type as any command registered
+// for the request type using RegisterCommand, otherwise callers will receive
+// a ResultTypeError for requests for the tenant.
+//
+// If the tenant is empty the function will return ErrNoTenant; requests
+// executed with no tenant are executed by the command registered using
+// RegisterCommand.
+//
+// If a command is already registered for the request type for the tenant the
+// function will return a CommandAlreadyRegisteredError.
+//
+// If the command being registered implements the ConfigurationChecker
+// interface, this is called and any error returned.
+//
+// Any OnRegister hooks established using AddHooks are called with the outcome.
+func RegisterTenantCommand[TRequest any, TResult any](ctx context.Context, tenant string, cmd CommandHandler[TRequest, TResult]) error {
+	_, err := registerTenant(ctx, tenant, *new(TRequest), cmd)
+	fireHook(ctx, onRegister, RegistrationEvent{RequestType: reflect.TypeOf(*new(TRequest)), Tenant: tenant, Handler: cmd, Err: err})
+	return err
+}
+
+// registerTenant registers a command for a tenant, returning a function which
+// removes the registration.  The registration is subject to the same checks
+// as registrations in the global registry.
+func registerTenant(ctx context.Context, tenant string, rq any, cmd any) (func(), error) {
+	if tenant == "" {
+		return nil, ErrNoTenant
+	}
+	return tenantCommands.register(ctx, commandKey{rqt: reflect.TypeOf(rq), tenant: tenant}, rq, cmd)
+}
+
+// lookupTenant returns the command registered for the specified request type
+// for the tenant identified by the context, if any.
+func lookupTenant(ctx context.Context, rqt reflect.Type) (any, bool) {
+	if tenantCommands.empty() {
+		return nil, false
+	}
+
+	t := tenant(ctx)
+	if t == "" {
+		return nil, false
+	}
+
+	return tenantCommands.lookup(commandKey{rqt: rqt, tenant: t})
+}
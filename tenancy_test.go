@@ -0,0 +1,216 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// tenancytestrequest is a request type used for testing tenant registrations.
+type tenancytestrequest struct{}
+
+// tenancytestcmd is a command returning a specific result.
+type tenancytestcmd string
+
+func (cmd tenancytestcmd) Execute(context.Context, tenancytestrequest) (string, error) {
+	return string(cmd), nil
+}
+
+// resetTenantCommands replaces the commands registered for tenants for the
+// duration of a test.
+func resetTenantCommands(t *testing.T) {
+	og := tenantCommands.commands
+	tenantCommands.commands = nil
+	t.Cleanup(func() { tenantCommands.commands = og })
+}
+
+func TestTenant(t *testing.T) {
+	// ARRANGE
+	ctx := ContextWithMetadata(context.Background(), Metadata{TenantID: "metadata"})
+
+	t.Run("default", func(t *testing.T) {
+		wanted := "metadata"
+		got := Tenant(ctx)
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("custom tenant function", func(t *testing.T) {
+		// ARRANGE
+		SetTenantFunc(func(context.Context) string { return "custom" })
+		defer SetTenantFunc(nil)
+
+		// ACT
+		result := Tenant(ctx)
+
+		// ASSERT
+		wanted := "custom"
+		got := result
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
+func TestRegisterTenantCommand(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+	rqt := reflect.TypeOf(tenancytestrequest{})
+
+	t.Run("executes command for tenant", func(t *testing.T) {
+		// ARRANGE
+		resetTenantCommands(t)
+		if err := RegisterCommand[tenancytestrequest, string](ctx, tenancytestcmd("default")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer delete(commands, rqt)
+		if err := RegisterTenantCommand[tenancytestrequest, string](ctx, "acme", tenancytestcmd("acme")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		testcases := []struct {
+			tenant string
+			result string
+		}{
+			{tenant: "", result: "default"},
+			{tenant: "acme", result: "acme"},
+			{tenant: "other", result: "default"},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.tenant, func(t *testing.T) {
+				// ARRANGE
+				ctx := ContextWithMetadata(ctx, Metadata{TenantID: tc.tenant})
+
+				// ACT
+				result, err := Execute(ctx, tenancytestrequest{}, new(string))
+
+				// ASSERT
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				wanted := tc.result
+				got := result
+				if wanted != got {
+					t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+				}
+			})
+		}
+
+		t.Run("registrations", func(t *testing.T) {
+			wanted := []RegistrationInfo{
+				{RequestType: "github.com/blugnu/mediator.tenancytestrequest", ResultType: "string", HandlerType: "github.com/blugnu/mediator.tenancytestcmd"},
+				{RequestType: "github.com/blugnu/mediator.tenancytestrequest", Tenant: "acme", ResultType: "string", HandlerType: "github.com/blugnu/mediator.tenancytestcmd"},
+			}
+			got := Registrations()
+			if !reflect.DeepEqual(wanted, got) {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("with no default registration", func(t *testing.T) {
+		// ARRANGE
+		resetTenantCommands(t)
+		_ = RegisterTenantCommand[tenancytestrequest, string](ctx, "acme", tenancytestcmd("acme"))
+
+		// ACT
+		_, err := Execute(ctx, tenancytestrequest{}, new(string))
+
+		// ASSERT
+		wanted := NoCommandForRequestTypeError{request: tenancytestrequest{}}
+		got := err
+		if !errors.Is(got, wanted) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when no tenant is specified", func(t *testing.T) {
+		// ARRANGE
+		resetTenantCommands(t)
+
+		// ACT
+		err := RegisterTenantCommand[tenancytestrequest, string](ctx, "", tenancytestcmd("none"))
+
+		// ASSERT
+		t.Run("returns error", func(t *testing.T) {
+			wanted := ErrNoTenant
+			got := err
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+
+		t.Run("does not register the command", func(t *testing.T) {
+			if !tenantCommands.empty() {
+				t.Error("command registered")
+			}
+		})
+	})
+
+	t.Run("when already registered for tenant", func(t *testing.T) {
+		// ARRANGE
+		resetTenantCommands(t)
+		_ = RegisterTenantCommand[tenancytestrequest, string](ctx, "acme", tenancytestcmd("acme"))
+
+		// ACT
+		err := RegisterTenantCommand[tenancytestrequest, string](ctx, "acme", tenancytestcmd("other"))
+
+		// ASSERT
+		wanted := CommandAlreadyRegisteredError{request: tenancytestrequest{}}
+		got := err
+		if !errors.Is(got, wanted) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("returns any ConfigurationChecker error", func(t *testing.T) {
+		// ARRANGE
+		resetTenantCommands(t)
+		cfgerr := errors.New("configuration error")
+
+		// ACT
+		err := RegisterTenantCommand[int, NoResultType](ctx, "acme", registrationtestcmd{cfgerr})
+
+		// ASSERT
+		wanted := cfgerr
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when the ConfigurationChecker executes a request", func(t *testing.T) {
+		// ARRANGE
+		resetTenantCommands(t)
+		_ = RegisterTenantCommand[tenancytestrequest, string](ctx, "acme", tenancytestcmd("acme"))
+		cmd := registrationexecutingtestcmd{exec: func(ctx context.Context) { _, _ = Execute(ctx, "request", new(int)) }}
+
+		// ACT
+		ok := completes(func() { _ = RegisterTenantCommand[int, NoResultType](ctx, "acme", cmd) })
+
+		// ASSERT
+		if !ok {
+			t.Error("registration did not complete")
+		}
+	})
+
+	t.Run("calls OnRegister hooks", func(t *testing.T) {
+		// ARRANGE
+		resetTenantCommands(t)
+		var event RegistrationEvent
+		remove := AddHooks(Hooks{OnRegister: func(_ context.Context, e RegistrationEvent) { event = e }})
+		defer remove()
+
+		// ACT
+		_ = RegisterTenantCommand[tenancytestrequest, string](ctx, "acme", tenancytestcmd("acme"))
+
+		// ASSERT
+		wanted := RegistrationEvent{RequestType: rqt, Tenant: "acme", Handler: tenancytestcmd("acme")}
+		got := event
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/blugnu/mediator/tools/internal/scan"
+)
+
+// catalog is a catalog of the commands in a module.
+type catalog struct {
+	Commands []*entry `json:"commands"`
+}
+
+// entry describes a command in the catalog.
+type entry struct {
+	Request             string     `json:"request"`                    // the request type
+	Doc                 string     `json:"doc,omitempty"`              // the doc comment of the request type
+	Fields              []field    `json:"fields,omitempty"`           // the fields of the request type, if a struct
+	Result              string     `json:"result"`                     // the result type
+	Handlers            []string   `json:"handlers,omitempty"`         // the types of the commands registered for the request type
+	Validates           bool       `json:"validates"`                  // true if a registered command implements Validator
+	ChecksConfiguration bool       `json:"checksConfiguration"`        // true if a registered command implements ConfigurationChecker
+	RegisteredAt        []string   `json:"registeredAt,omitempty"`     // the positions at which the command is registered
+	Callers             []string   `json:"callers,omitempty"`          // the packages which execute the request type
+	ResultMismatches    []string   `json:"resultMismatches,omitempty"` // result types expected by callers which differ from the registered result type
+	TenantOverrides     []override `json:"tenantOverrides,omitempty"`  // the commands registered for specific tenants
+	NamedCommands       []override `json:"namedCommands,omitempty"`    // the commands registered with a name
+	Interface           bool       `json:"interface,omitempty"`        // true if the request type is an interface for which a command is registered
+	Interfaces          []string   `json:"interfaces,omitempty"`       // the interfaces implemented by the request type for which commands are registered
+	Equivalent          string     `json:"equivalent,omitempty"`       // the registered type to which the request type points, if executed with pointer equivalence
+}
+
+// override describes a command registered for a request type for a specific
+// tenant, overriding the command registered for all tenants, or with a name.
+type override struct {
+	Tenant       string `json:"tenant,omitempty"`  // the tenant, or the expression identifying it if dynamic
+	Name         string `json:"name,omitempty"`    // the name, or the expression identifying it if dynamic
+	Dynamic      bool   `json:"dynamic,omitempty"` // true if the tenant or name is determined at runtime
+	Handler      string `json:"handler,omitempty"` // the type of the command registered for the tenant
+	RegisteredAt string `json:"registeredAt"`      // the position at which the command is registered
+}
+
+// field describes a field of a request type.
+type field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Tag  string `json:"tag,omitempty"`
+	Doc  string `json:"doc,omitempty"`
+}
+
+// build returns the catalog of the commands registered and executed in the
+// specified packages.  Positions are reported relative to the specified
+// directory.
+func build(pkgs []*packages.Package, dir string) *catalog {
+	docs := typeDocs{}
+	wrappers := map[*types.Func]scan.Use{}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		docs.add(pkg)
+		for fn, u := range scan.Wrappers(pkg.Syntax, pkg.TypesInfo) {
+			wrappers[fn] = u
+		}
+	})
+
+	entries := map[string]*entry{}
+	get := func(rq types.Type, result types.Type) *entry {
+		name := scan.TypeName(rq)
+		e, ok := entries[name]
+		if !ok {
+			e = &entry{Request: name, Result: scan.TypeName(result)}
+			e.Doc, e.Fields = docs.describe(rq)
+			entries[name] = e
+		}
+		return e
+	}
+
+	executions := []scan.Use{}
+	ifaces := []types.Type{}
+	equivalence := false
+	callers := map[scan.Use]string{}
+	for _, pkg := range pkgs {
+		uses := scan.Package(pkg.Fset, pkg.Syntax, pkg.TypesInfo)
+		uses = append(uses, wrapperCalls(pkg, wrappers)...)
+		for _, u := range uses {
+			switch u.Kind {
+			case scan.Registration:
+				e := get(u.Request, u.Result)
+				e.Result = scan.TypeName(u.Result)
+				o := override{
+					Tenant:       u.Tenant,
+					Name:         u.Name,
+					Dynamic:      u.Dynamic,
+					Handler:      scan.TypeName(u.Command),
+					RegisteredAt: position(pkg.Fset, u.Pos, dir),
+				}
+				switch {
+				case u.Tenant != "":
+					e.TenantOverrides = append(e.TenantOverrides, o)
+					continue
+				case u.Name != "":
+					e.NamedCommands = append(e.NamedCommands, o)
+					continue
+				}
+				e.RegisteredAt = append(e.RegisteredAt, position(pkg.Fset, u.Pos, dir))
+				if u.Interface {
+					e.Interface = true
+					ifaces = append(ifaces, u.Request)
+				}
+				if u.Command != nil {
+					e.Handlers = appendUnique(e.Handlers, scan.TypeName(u.Command))
+					e.Validates = e.Validates || implements(u.Command, "Validate", u.Request)
+					e.ChecksConfiguration = e.ChecksConfiguration || implements(u.Command, "CheckConfiguration", nil)
+				}
+			case scan.Execution:
+				executions = append(executions, u)
+				callers[u] = pkg.PkgPath
+			case scan.Equivalence:
+				equivalence = true
+			}
+		}
+	}
+
+	// executions are processed once all registrations are known, so that the
+	// result types expected by callers can be compared with the registered
+	// result type
+	for _, u := range executions {
+		e := get(u.Request, u.Result)
+		e.Callers = appendUnique(e.Callers, callers[u])
+		if target := equivalent(entries, e, u, equivalence); target != nil {
+			e.Equivalent, e.Result = target.Request, target.Result
+		}
+		if result := scan.TypeName(u.Result); (e.registered() || e.Equivalent != "") && result != e.Result {
+			e.ResultMismatches = appendUnique(e.ResultMismatches, result)
+		}
+		if !e.registered() && e.Equivalent == "" && u.Name == "" {
+			for _, iface := range ifaces {
+				if scan.Implements(u.Request, iface) {
+					e.Interfaces = appendUnique(e.Interfaces, scan.TypeName(iface))
+				}
+			}
+		}
+	}
+
+	c := &catalog{Commands: []*entry{}}
+	for _, e := range entries {
+		sort.Strings(e.RegisteredAt)
+		sort.Strings(e.Callers)
+		sort.Strings(e.Interfaces)
+		sortOverrides(e.TenantOverrides)
+		sortOverrides(e.NamedCommands)
+		c.Commands = append(c.Commands, e)
+	}
+	sort.Slice(c.Commands, func(i, j int) bool { return c.Commands[i].Request < c.Commands[j].Request })
+	return c
+}
+
+// registered returns true if a command is registered for the request type of
+// an entry, for all tenants, a specific tenant or with a name.
+func (e *entry) registered() bool {
+	return len(e.RegisteredAt) > 0 || len(e.TenantOverrides) > 0 || len(e.NamedCommands) > 0
+}
+
+// equivalent returns the entry of the type to which the request type of an
+// execution points, if pointer equivalence is enabled and a command (other
+// than a named command) is registered for that type but not for the request
+// type.  Requests executed with a name are not subject to pointer equivalence.
+func equivalent(entries map[string]*entry, e *entry, u scan.Use, enabled bool) *entry {
+	p, ok := u.Request.(*types.Pointer)
+	if !enabled || !ok || u.Name != "" || e.registered() {
+		return nil
+	}
+	target, ok := entries[scan.TypeName(p.Elem())]
+	if !ok || target.Interface || (len(target.RegisteredAt) == 0 && len(target.TenantOverrides) == 0) {
+		return nil
+	}
+	return target
+}
+
+// sortOverrides sorts overrides by tenant or name and the position at which
+// they are registered.
+func sortOverrides(o []override) {
+	sort.Slice(o, func(i, j int) bool {
+		a, b := o[i], o[j]
+		switch {
+		case a.Tenant != b.Tenant:
+			return a.Tenant < b.Tenant
+		case a.Name != b.Name:
+			return a.Name < b.Name
+		}
+		return a.RegisteredAt < b.RegisteredAt
+	})
+}
+
+// wrapperCalls returns the uses of the mediator by calls to typed wrappers in
+// the non-generated files of a package.
+func wrapperCalls(pkg *packages.Package, wrappers map[*types.Func]scan.Use) []scan.Use {
+	result := []scan.Use{}
+	for _, file := range pkg.Syntax {
+		if scan.Test(pkg.Fset, file) || scan.Generated(file) {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			fn := typeutil.StaticCallee(pkg.TypesInfo, call)
+			if u, ok := wrappers[fn]; ok && fn != nil {
+				u.Pos = call.Pos()
+				if u.Kind == scan.Registration && len(call.Args) > 1 {
+					u.Command = pkg.TypesInfo.TypeOf(call.Args[len(call.Args)-1])
+				}
+				result = append(result, u)
+			}
+			return true
+		})
+	}
+	return result
+}
+
+// implements returns true if the specified type (or a pointer to it) has a
+// method with the specified name.  If a request type is specified, the second
+// parameter of the method must be of that type.
+func implements(t types.Type, method string, rq types.Type) bool {
+	if _, ok := t.(*types.Pointer); !ok {
+		if _, isIface := t.Underlying().(*types.Interface); !isIface {
+			t = types.NewPointer(t)
+		}
+	}
+	sel := types.NewMethodSet(t).Lookup(nil, method)
+	if sel == nil {
+		return false
+	}
+	if rq == nil {
+		return true
+	}
+	sig, ok := sel.Type().(*types.Signature)
+	return ok && sig.Params().Len() == 2 && types.Identical(sig.Params().At(1).Type(), rq)
+}
+
+// position returns a position as a path relative to the specified directory,
+// with the line number.
+func position(fset *token.FileSet, pos token.Pos, dir string) string {
+	p := fset.Position(pos)
+	if rel, err := filepath.Rel(dir, p.Filename); err == nil {
+		p.Filename = filepath.ToSlash(rel)
+	}
+	p.Column = 0
+	return p.String()
+}
+
+// appendUnique appends a string to a slice if not already present.
+func appendUnique(s []string, v string) []string {
+	for _, each := range s {
+		if each == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
+
+// typeDocs indexes the declarations of named types in the loaded packages, to
+// obtain doc comments.
+type typeDocs map[*types.TypeName]typeDecl
+
+// typeDecl is the declaration of a named type.
+type typeDecl struct {
+	spec *ast.TypeSpec
+	doc  *ast.CommentGroup
+}
+
+// add indexes the type declarations in a package.
+func (d typeDocs) add(pkg *packages.Package) {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				tn, ok := pkg.TypesInfo.Defs[ts.Name].(*types.TypeName)
+				if !ok {
+					continue
+				}
+				doc := ts.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+				d[tn] = typeDecl{spec: ts, doc: doc}
+			}
+		}
+	}
+}
+
+// describe returns the doc comment and fields of a request type.
+func (d typeDocs) describe(t types.Type) (string, []field) {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return "", nil
+	}
+	decl := d[named.Obj()]
+
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return text(decl.doc), nil
+	}
+
+	// field syntax, for doc comments, if the type is declared in a loaded package
+	syntax := map[string]*ast.Field{}
+	if decl.spec != nil {
+		if stx, ok := decl.spec.Type.(*ast.StructType); ok {
+			for _, f := range stx.Fields.List {
+				for _, name := range f.Names {
+					syntax[name.Name] = f
+				}
+			}
+		}
+	}
+
+	fields := []field{}
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		if !v.Exported() {
+			continue
+		}
+		f := field{
+			Name: v.Name(),
+			Type: types.TypeString(v.Type(), types.RelativeTo(named.Obj().Pkg())),
+			Tag:  st.Tag(i),
+		}
+		if fs, ok := syntax[v.Name()]; ok {
+			f.Doc = text(fs.Doc)
+			if f.Doc == "" {
+				f.Doc = text(fs.Comment)
+			}
+		}
+		fields = append(fields, f)
+	}
+	return text(decl.doc), fields
+}
+
+// text returns the text of a comment group, without a trailing newline.
+func text(cg *ast.CommentGroup) string {
+	return strings.TrimSpace(cg.Text())
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	// ARRANGE
+	dir, err := filepath.Abs(filepath.Join("testdata", "example"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// ACT
+	c, err := load(dir, nil)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wanted := []*entry{
+		{
+			Request:    "*example.com/example/orders.PlaceOrder",
+			Result:     "*example.com/example/orders.Order",
+			Callers:    []string{"example.com/example/app"},
+			Equivalent: "example.com/example/orders.PlaceOrder",
+		},
+		{
+			Request:      "example.com/example/audit.Auditable",
+			Doc:          "Auditable is implemented by requests which are audited.",
+			Result:       "github.com/blugnu/mediator.NoResultType",
+			Handlers:     []string{"example.com/example/audit.Command"},
+			RegisteredAt: []string{"audit/audit.go:28"},
+			Interface:    true,
+		},
+		{
+			Request:    "example.com/example/audit.Purge",
+			Doc:        "Purge is a request to purge the audit log.",
+			Fields:     []field{},
+			Result:     "github.com/blugnu/mediator.NoResultType",
+			Callers:    []string{"example.com/example/app"},
+			Interfaces: []string{"example.com/example/audit.Auditable"},
+		},
+		{
+			Request: "example.com/example/billing.Invoice",
+			Doc:     "Invoice is a request to invoice an order.",
+			Fields:  []field{{Name: "OrderId", Type: "string"}},
+			Result:  "github.com/blugnu/mediator.NoResultType",
+			Callers: []string{"example.com/example/billing"},
+		},
+		{
+			Request:          "example.com/example/orders.CancelOrder",
+			Doc:              "CancelOrder cancels an order.",
+			Result:           "github.com/blugnu/mediator.NoResultType",
+			Handlers:         []string{"example.com/example/orders.CancelOrderHandler"},
+			RegisteredAt:     []string{"orders/orders.go:54"},
+			Callers:          []string{"example.com/example/app"},
+			ResultMismatches: []string{"*int"},
+			TenantOverrides: []override{{
+				Tenant:       "acme",
+				Handler:      "example.com/example/orders.AcmeCancelOrderHandler",
+				RegisteredAt: "orders/orders.go:57",
+			}},
+		},
+		{
+			Request: "example.com/example/orders.PlaceOrder",
+			Doc:     "PlaceOrder places an order for a product.",
+			Fields: []field{
+				{Name: "Product", Type: "string", Tag: `json:"product"`, Doc: "the product to be ordered"},
+				{Name: "Quantity", Type: "int", Doc: "the number of items ordered"},
+			},
+			Result:              "*example.com/example/orders.Order",
+			Handlers:            []string{"*example.com/example/orders.PlaceOrderHandler"},
+			Validates:           true,
+			ChecksConfiguration: true,
+			RegisteredAt:        []string{"orders/orders.go:51"},
+			Callers:             []string{"example.com/example/billing"},
+		},
+		{
+			Request: "example.com/example/payments.Charge",
+			Doc:     "Charge is a request to charge a payment.",
+			Fields:  []field{{Name: "Amount", Type: "int"}},
+			Result:  "string",
+			Callers: []string{"example.com/example/app"},
+			NamedCommands: []override{
+				{Name: "paypal", Handler: "example.com/example/payments.PaypalCommand", RegisteredAt: "payments/payments.go:33"},
+				{Name: "stripe", Handler: "example.com/example/payments.StripeCommand", RegisteredAt: "payments/payments.go:30"},
+			},
+		},
+		{
+			Request:      "example.com/example/shipping.Request",
+			Doc:          "Request is a request to ship an order.",
+			Fields:       []field{{Name: "OrderId", Type: "string"}},
+			Result:       "bool",
+			Handlers:     []string{"*example.com/example/shipping.Command"},
+			RegisteredAt: []string{"app/main.go:17"},
+			Callers:      []string{"example.com/example/app"},
+		},
+	}
+	got := c.Commands
+	if len(wanted) != len(got) {
+		t.Fatalf("\nwanted %d commands\ngot    %d", len(wanted), len(got))
+	}
+	for i := range wanted {
+		if !reflect.DeepEqual(wanted[i], got[i]) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted[i], got[i])
+		}
+	}
+}
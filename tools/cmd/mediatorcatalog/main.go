@@ -0,0 +1,112 @@
+// Command mediatorcatalog produces a catalog of the commands in a module,
+// derived from the registration and execution of requests in the source.
+//
+// For each request type the catalog describes:
+//
+//   - the request type, with its doc comment and exported fields;
+//   - the result type;
+//   - the type of the registered command (handler), and whether it
+//     validates requests and/or checks its configuration;
+//   - where the command is registered;
+//   - the packages which execute the request;
+//   - any commands registered for specific tenants or with a name;
+//   - for a request type with no registered command, any interfaces it
+//     implements for which commands are registered or, if pointer
+//     equivalence is enabled, the registered type to which it points.
+//
+// Registrations are identified by calls to mediator.RegisterCommand,
+// mediator.RegisterTenantCommand, mediator.RegisterNamedCommand and
+// mediator.RegisterInterfaceCommand, and executions by calls to
+// mediator.Execute and mediator.ExecuteNamed (or the typed wrappers generated
+// by mediatorgen), in non-test files.  Pointer equivalence is enabled by a
+// call to mediator.SetPointerEquivalence in any of the packages.
+//
+// Usage:
+//
+//	mediatorcatalog [flags] [packages]
+//
+// Packages are specified as for the go command; the default is ./... (all
+// packages in the module in the current directory).
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func main() {
+	format := flag.String("format", "markdown", "the format of the catalog (markdown or json)")
+	output := flag.String("o", "", "the file to which the catalog is written (default stdout)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: mediatorcatalog [flags] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*format, *output, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "mediatorcatalog: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run writes the catalog of the commands in the specified packages.
+func run(format string, output string, patterns []string) error {
+	var write func(io.Writer, *catalog) error
+	switch format {
+	case "markdown", "md":
+		write = writeMarkdown
+	case "json":
+		write = writeJSON
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	c, err := load(dir, patterns)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := write(buf, c); err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = buf.WriteTo(os.Stdout)
+		return err
+	}
+	return os.WriteFile(output, buf.Bytes(), 0o644)
+}
+
+// load loads the specified packages (relative to a directory) and returns the
+// catalog of the commands they register and execute.
+func load(dir string, patterns []string) (*catalog, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir: dir,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("%s: %v", pkg.PkgPath, pkg.Errors[0])
+		}
+	}
+	return build(pkgs, dir), nil
+}
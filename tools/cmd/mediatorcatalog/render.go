@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeJSON writes a catalog as indented JSON.
+func writeJSON(w io.Writer, c *catalog) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// writeMarkdown writes a catalog as a Markdown document.
+func writeMarkdown(w io.Writer, c *catalog) error {
+	b := &strings.Builder{}
+	b.WriteString("# Command Catalog\n")
+
+	if len(c.Commands) == 0 {
+		b.WriteString("\nNo commands were found.\n")
+	}
+
+	for _, e := range c.Commands {
+		fmt.Fprintf(b, "\n## `%s`\n\n", e.Request)
+		if e.Doc != "" {
+			fmt.Fprintf(b, "%s\n\n", e.Doc)
+		}
+
+		if len(e.Fields) > 0 {
+			b.WriteString("| Field | Type | Tag | Description |\n")
+			b.WriteString("|-------|------|-----|-------------|\n")
+			for _, f := range e.Fields {
+				tag := ""
+				if f.Tag != "" {
+					tag = "`" + f.Tag + "`"
+				}
+				fmt.Fprintf(b, "| %s | `%s` | %s | %s |\n", f.Name, f.Type, tag, cell(f.Doc))
+			}
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(b, "- **Result:** `%s`\n", e.Result)
+		if e.Interface {
+			b.WriteString("- **Interface:** executes requests of any type implementing the interface\n")
+		}
+		switch {
+		case e.Equivalent != "":
+			fmt.Fprintf(b, "- **Handler:** registered for `%s` (pointer equivalence)\n", e.Equivalent)
+		case len(e.Interfaces) > 0:
+			fmt.Fprintf(b, "- **Handler:** registered for interface %s\n", code(e.Interfaces))
+		case len(e.Handlers) == 0 && !e.registered():
+			b.WriteString("- **Handler:** _not registered_\n")
+		case len(e.Handlers) > 0:
+			fmt.Fprintf(b, "- **Handler:** %s\n", code(e.Handlers))
+			fmt.Fprintf(b, "- **Validates:** %s\n", yesNo(e.Validates))
+			fmt.Fprintf(b, "- **Checks configuration:** %s\n", yesNo(e.ChecksConfiguration))
+			fmt.Fprintf(b, "- **Registered at:** %s\n", code(e.RegisteredAt))
+		}
+		if len(e.TenantOverrides) > 0 {
+			b.WriteString("- **Tenant overrides:**\n")
+			for _, o := range e.TenantOverrides {
+				writeOverride(b, o.Tenant, o)
+			}
+		}
+		if len(e.NamedCommands) > 0 {
+			b.WriteString("- **Named commands:**\n")
+			for _, o := range e.NamedCommands {
+				writeOverride(b, o.Name, o)
+			}
+		}
+		if len(e.Callers) > 0 {
+			fmt.Fprintf(b, "- **Called by:** %s\n", code(e.Callers))
+		}
+		if len(e.ResultMismatches) > 0 {
+			fmt.Fprintf(b, "- **Called expecting different result:** %s\n", code(e.ResultMismatches))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeOverride writes a command registered for a tenant or with a name, as an
+// item of a nested list.
+func writeOverride(b *strings.Builder, key string, o override) {
+	key = "`" + key + "`"
+	if o.Dynamic {
+		key += " (determined at runtime)"
+	}
+	fmt.Fprintf(b, "  - %s: `%s` (registered at `%s`)\n", key, o.Handler, o.RegisteredAt)
+}
+
+// cell returns text formatted for a Markdown table cell.
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// code returns a comma separated list of strings formatted as code.
+func code(s []string) string {
+	return "`" + strings.Join(s, "`, `") + "`"
+}
+
+// yesNo returns "yes" or "no".
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	testcases := []struct {
+		scenario string
+		catalog  *catalog
+		result   string
+	}{
+		{scenario: "no commands",
+			catalog: &catalog{},
+			result:  "# Command Catalog\n\nNo commands were found.\n",
+		},
+		{scenario: "registered command",
+			catalog: &catalog{Commands: []*entry{{
+				Request:      "example.com/foo.Request",
+				Doc:          "Request is a request for a foo.",
+				Fields:       []field{{Name: "Id", Type: "string", Tag: `json:"id"`, Doc: "the id | key"}},
+				Result:       "*example.com/foo.Foo",
+				Handlers:     []string{"*example.com/foo.Command"},
+				Validates:    true,
+				RegisteredAt: []string{"foo/foo.go:10", "foo/foo.go:20"},
+				Callers:      []string{"example.com/bar"},
+			}}},
+			result: "# Command Catalog\n" +
+				"\n## `example.com/foo.Request`\n\n" +
+				"Request is a request for a foo.\n\n" +
+				"| Field | Type | Tag | Description |\n" +
+				"|-------|------|-----|-------------|\n" +
+				"| Id | `string` | `json:\"id\"` | the id \\| key |\n\n" +
+				"- **Result:** `*example.com/foo.Foo`\n" +
+				"- **Handler:** `*example.com/foo.Command`\n" +
+				"- **Validates:** yes\n" +
+				"- **Checks configuration:** no\n" +
+				"- **Registered at:** `foo/foo.go:10`, `foo/foo.go:20`\n" +
+				"- **Called by:** `example.com/bar`\n",
+		},
+		{scenario: "tenant overrides",
+			catalog: &catalog{Commands: []*entry{{
+				Request:      "example.com/foo.Request",
+				Result:       "int",
+				Handlers:     []string{"example.com/foo.Command"},
+				RegisteredAt: []string{"foo/foo.go:10"},
+				TenantOverrides: []override{
+					{Tenant: "acme", Handler: "example.com/foo.AcmeCommand", RegisteredAt: "foo/foo.go:20"},
+					{Tenant: "cfg.Tenant", Dynamic: true, Handler: "example.com/foo.TenantCommand", RegisteredAt: "foo/foo.go:30"},
+				},
+			}}},
+			result: "# Command Catalog\n" +
+				"\n## `example.com/foo.Request`\n\n" +
+				"- **Result:** `int`\n" +
+				"- **Handler:** `example.com/foo.Command`\n" +
+				"- **Validates:** no\n" +
+				"- **Checks configuration:** no\n" +
+				"- **Registered at:** `foo/foo.go:10`\n" +
+				"- **Tenant overrides:**\n" +
+				"  - `acme`: `example.com/foo.AcmeCommand` (registered at `foo/foo.go:20`)\n" +
+				"  - `cfg.Tenant` (determined at runtime): `example.com/foo.TenantCommand` (registered at `foo/foo.go:30`)\n",
+		},
+		{scenario: "named commands",
+			catalog: &catalog{Commands: []*entry{{
+				Request: "example.com/foo.Request",
+				Result:  "int",
+				NamedCommands: []override{
+					{Name: "stripe", Handler: "example.com/foo.StripeCommand", RegisteredAt: "foo/foo.go:20"},
+				},
+			}}},
+			result: "# Command Catalog\n" +
+				"\n## `example.com/foo.Request`\n\n" +
+				"- **Result:** `int`\n" +
+				"- **Named commands:**\n" +
+				"  - `stripe`: `example.com/foo.StripeCommand` (registered at `foo/foo.go:20`)\n",
+		},
+		{scenario: "interface command",
+			catalog: &catalog{Commands: []*entry{{
+				Request:      "example.com/foo.Auditable",
+				Result:       "int",
+				Handlers:     []string{"example.com/foo.AuditCommand"},
+				RegisteredAt: []string{"foo/foo.go:10"},
+				Interface:    true,
+			}}},
+			result: "# Command Catalog\n" +
+				"\n## `example.com/foo.Auditable`\n\n" +
+				"- **Result:** `int`\n" +
+				"- **Interface:** executes requests of any type implementing the interface\n" +
+				"- **Handler:** `example.com/foo.AuditCommand`\n" +
+				"- **Validates:** no\n" +
+				"- **Checks configuration:** no\n" +
+				"- **Registered at:** `foo/foo.go:10`\n",
+		},
+		{scenario: "command registered for interface",
+			catalog: &catalog{Commands: []*entry{{
+				Request:    "example.com/foo.Request",
+				Result:     "int",
+				Callers:    []string{"example.com/bar"},
+				Interfaces: []string{"example.com/foo.Auditable"},
+			}}},
+			result: "# Command Catalog\n" +
+				"\n## `example.com/foo.Request`\n\n" +
+				"- **Result:** `int`\n" +
+				"- **Handler:** registered for interface `example.com/foo.Auditable`\n" +
+				"- **Called by:** `example.com/bar`\n",
+		},
+		{scenario: "command executed with pointer equivalence",
+			catalog: &catalog{Commands: []*entry{{
+				Request:    "*example.com/foo.Request",
+				Result:     "int",
+				Callers:    []string{"example.com/bar"},
+				Equivalent: "example.com/foo.Request",
+			}}},
+			result: "# Command Catalog\n" +
+				"\n## `*example.com/foo.Request`\n\n" +
+				"- **Result:** `int`\n" +
+				"- **Handler:** registered for `example.com/foo.Request` (pointer equivalence)\n" +
+				"- **Called by:** `example.com/bar`\n",
+		},
+		{scenario: "unregistered command",
+			catalog: &catalog{Commands: []*entry{{
+				Request: "example.com/foo.Request",
+				Result:  "int",
+				Callers: []string{"example.com/bar"},
+			}}},
+			result: "# Command Catalog\n" +
+				"\n## `example.com/foo.Request`\n\n" +
+				"- **Result:** `int`\n" +
+				"- **Handler:** _not registered_\n" +
+				"- **Called by:** `example.com/bar`\n",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			buf := &bytes.Buffer{}
+
+			// ACT
+			err := writeMarkdown(buf, tc.catalog)
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			wanted := tc.result
+			got := buf.String()
+			if wanted != got {
+				t.Errorf("\nwanted %s\ngot    %s", wanted, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+
+	"example.com/example/audit"
+	"example.com/example/billing"
+	"example.com/example/orders"
+	"example.com/example/payments"
+	"example.com/example/shipping"
+	"github.com/blugnu/mediator"
+)
+
+func main() {
+	ctx := context.Background()
+	_ = orders.Register(ctx)
+	_ = shipping.Register(ctx, &shipping.Command{})
+	_ = billing.Bill(ctx, "widget")
+	_, _ = shipping.Execute(ctx, shipping.Request{OrderId: "1"})
+	_, _ = mediator.Execute(ctx, orders.CancelOrder("1"), new(*int))
+	_ = payments.Register(ctx)
+	_, _ = mediator.ExecuteNamed(ctx, "stripe", payments.Charge{Amount: 1}, new(string))
+	_ = audit.Register(ctx)
+	_, _ = mediator.Execute(ctx, audit.Purge{}, mediator.NoResult)
+	mediator.SetPointerEquivalence(true)
+	_, _ = mediator.Execute(ctx, &orders.PlaceOrder{Product: "gadget", Quantity: 1}, new(*orders.Order))
+}
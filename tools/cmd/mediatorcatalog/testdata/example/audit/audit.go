@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+)
+
+// Auditable is implemented by requests which are audited.
+type Auditable interface {
+	Audit() string
+}
+
+// Command audits Auditable requests.
+type Command struct{}
+
+func (Command) Execute(context.Context, Auditable) (mediator.NoResultType, error) {
+	return nil, nil
+}
+
+// Purge is a request to purge the audit log.
+type Purge struct{}
+
+func (Purge) Audit() string { return "purge" }
+
+// Register registers the audit command.
+func Register(ctx context.Context) error {
+	return mediator.RegisterInterfaceCommand[Auditable, mediator.NoResultType](ctx, 0, Command{})
+}
@@ -0,0 +1,23 @@
+package billing
+
+import (
+	"context"
+
+	"example.com/example/orders"
+	"github.com/blugnu/mediator"
+)
+
+// Invoice is a request to invoice an order.
+type Invoice struct {
+	OrderId string
+}
+
+// Bill places and invoices an order.
+func Bill(ctx context.Context, product string) error {
+	order, err := mediator.Execute(ctx, orders.PlaceOrder{Product: product, Quantity: 1}, new(*orders.Order))
+	if err != nil {
+		return err
+	}
+	_, err = mediator.Execute(ctx, Invoice{OrderId: order.Id}, new(mediator.NoResultType))
+	return err
+}
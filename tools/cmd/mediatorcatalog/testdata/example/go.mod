@@ -0,0 +1,7 @@
+module example.com/example
+
+go 1.18
+
+require github.com/blugnu/mediator v0.0.0
+
+replace github.com/blugnu/mediator => ../../../../..
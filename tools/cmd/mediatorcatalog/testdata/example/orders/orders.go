@@ -0,0 +1,65 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	"github.com/blugnu/mediator"
+)
+
+// PlaceOrder places an order for a product.
+type PlaceOrder struct {
+	// the product to be ordered
+	Product  string `json:"product"`
+	Quantity int    // the number of items ordered
+	internal bool
+}
+
+// Order is the result of placing an order.
+type Order struct {
+	Id string
+}
+
+// PlaceOrderHandler handles PlaceOrder requests.
+type PlaceOrderHandler struct{}
+
+func (*PlaceOrderHandler) CheckConfiguration(context.Context) error { return nil }
+
+func (*PlaceOrderHandler) Validate(_ context.Context, rq PlaceOrder) error {
+	if rq.Quantity < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+	return nil
+}
+
+func (*PlaceOrderHandler) Execute(context.Context, PlaceOrder) (*Order, error) {
+	return &Order{Id: "1"}, nil
+}
+
+// CancelOrder cancels an order.
+type CancelOrder string
+
+// CancelOrderHandler handles CancelOrder requests.
+type CancelOrderHandler struct{}
+
+func (CancelOrderHandler) Execute(context.Context, CancelOrder) (mediator.NoResultType, error) {
+	return nil, nil
+}
+
+// Register registers the order commands.
+func Register(ctx context.Context) error {
+	if err := mediator.RegisterCommand[PlaceOrder, *Order](ctx, &PlaceOrderHandler{}); err != nil {
+		return err
+	}
+	if err := mediator.RegisterCommand[CancelOrder, mediator.NoResultType](ctx, CancelOrderHandler{}); err != nil {
+		return err
+	}
+	return mediator.RegisterTenantCommand[CancelOrder, mediator.NoResultType](ctx, "acme", AcmeCancelOrderHandler{})
+}
+
+// AcmeCancelOrderHandler handles CancelOrder requests for the acme tenant.
+type AcmeCancelOrderHandler struct{}
+
+func (AcmeCancelOrderHandler) Execute(context.Context, CancelOrder) (mediator.NoResultType, error) {
+	return nil, nil
+}
@@ -0,0 +1,34 @@
+package payments
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+)
+
+// Charge is a request to charge a payment.
+type Charge struct {
+	Amount int
+}
+
+// StripeCommand charges payments using Stripe.
+type StripeCommand struct{}
+
+func (StripeCommand) Execute(context.Context, Charge) (string, error) {
+	return "stripe", nil
+}
+
+// PaypalCommand charges payments using PayPal.
+type PaypalCommand struct{}
+
+func (PaypalCommand) Execute(context.Context, Charge) (string, error) {
+	return "paypal", nil
+}
+
+// Register registers the payment commands.
+func Register(ctx context.Context) error {
+	if err := mediator.RegisterNamedCommand[Charge, string](ctx, "stripe", StripeCommand{}); err != nil {
+		return err
+	}
+	return mediator.RegisterNamedCommand[Charge, string](ctx, "paypal", PaypalCommand{})
+}
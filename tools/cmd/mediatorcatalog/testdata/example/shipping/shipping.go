@@ -0,0 +1,15 @@
+package shipping
+
+import "context"
+
+// Request is a request to ship an order.
+type Request struct {
+	OrderId string
+}
+
+// Command handles shipping requests.
+type Command struct{}
+
+func (*Command) Execute(context.Context, Request) (bool, error) {
+	return true, nil
+}
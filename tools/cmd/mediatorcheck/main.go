@@ -0,0 +1,18 @@
+// Command mediatorcheck checks the registration and execution of mediator
+// commands.  It is run using go vet:
+//
+//	go install github.com/blugnu/mediator/tools/cmd/mediatorcheck@latest
+//	go vet -vettool=$(which mediatorcheck) ./...
+//
+// See the mediatorcheck package for the checks performed.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/blugnu/mediator/tools/mediatorcheck"
+)
+
+func main() {
+	unitchecker.Main(mediatorcheck.Analyzer)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/blugnu/mediator/tools/internal/scan"
+)
+
+// mediatorPath is the import path of the mediator package.
+const mediatorPath = scan.MediatorPath
+
+// options are the options for generating the wrappers of a packaged command.
+type options struct {
+	request  string // the name of the request type
+	output   string // the name of the generated file
+	execute  string // the name of the generated execute function
+	register string // the name of the generated register function
+}
+
+// command describes a packaged command for which wrappers are generated.
+type command struct {
+	Package  string   // the name of the package
+	Imports  []string // the import paths required by the generated code
+	Request  string   // the name of the request type
+	Result   string   // the result type, qualified relative to the package
+	Handlers string   // the types in the package which handle the request
+	Execute  string   // the name of the execute function
+	Register string   // the name of the register function
+}
+
+// errNoRequest is returned when a package does not declare a request type.
+var errNoRequest = errors.New("no request type")
+
+// generate returns the source of the wrappers for the packaged command in the
+// specified package.  If the package does not declare the request type,
+// errNoRequest is returned.
+func generate(fset *token.FileSet, pkg *types.Package, opts options) ([]byte, error) {
+	rq, ok := pkg.Scope().Lookup(opts.request).(*types.TypeName)
+	if !ok {
+		return nil, errNoRequest
+	}
+
+	// the names of the generated functions must not be declared elsewhere
+	for _, name := range []string{opts.execute, opts.register} {
+		if obj := pkg.Scope().Lookup(name); obj != nil && filepath.Base(fset.Position(obj.Pos()).Filename) != opts.output {
+			return nil, fmt.Errorf("%s: %s is already declared in package %s", fset.Position(obj.Pos()), name, pkg.Name())
+		}
+	}
+
+	result, handlers, err := handlers(pkg, rq.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	imports := map[string]bool{"context": true, mediatorPath: true}
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		imports[p.Path()] = true
+		return p.Name()
+	}
+
+	cmd := command{
+		Package:  pkg.Name(),
+		Request:  opts.request,
+		Result:   types.TypeString(result, qualifier),
+		Handlers: strings.Join(handlers, ", "),
+		Execute:  opts.execute,
+		Register: opts.register,
+	}
+	for path := range imports {
+		cmd.Imports = append(cmd.Imports, path)
+	}
+	sort.Strings(cmd.Imports)
+
+	buf := bytes.NewBufferString(scan.GeneratedHeader + "\n\n")
+	if err := wrappers.Execute(buf, cmd); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// handlers returns the result type of the handlers of the request type declared
+// in the package, with the names of the handler types.  An error is returned if
+// there are no handlers or the handlers return different result types.
+func handlers(pkg *types.Package, rq types.Type) (types.Type, []string, error) {
+	var result types.Type
+	names := []string{}
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() || types.IsInterface(tn.Type()) {
+			continue
+		}
+
+		r, ptr, ok := executeResult(tn.Type(), rq)
+		if !ok {
+			continue
+		}
+		if ptr {
+			name = "*" + name
+		}
+		if result != nil && !types.Identical(result, r) {
+			return nil, nil, fmt.Errorf("handlers of %s return different result types: %s and %s", rq, result, r)
+		}
+		result = r
+		names = append(names, name)
+	}
+
+	if result == nil {
+		return nil, nil, fmt.Errorf("no handler for %s: no type declares Execute(context.Context, %s) (TResult, error)", rq, types.TypeString(rq, types.RelativeTo(pkg)))
+	}
+	return result, names, nil
+}
+
+// executeResult returns the result type of the Execute method of a type (or a
+// pointer to the type), if the method handles the specified request type.  ptr
+// is true if the method is declared with a pointer receiver.
+func executeResult(t types.Type, rq types.Type) (result types.Type, ptr bool, ok bool) {
+	sel := types.NewMethodSet(types.NewPointer(t)).Lookup(nil, "Execute")
+	if sel == nil {
+		return nil, false, false
+	}
+
+	sig, ok := sel.Type().(*types.Signature)
+	if !ok || sig.Params().Len() != 2 || sig.Results().Len() != 2 {
+		return nil, false, false
+	}
+	if sig.Params().At(0).Type().String() != "context.Context" ||
+		!types.Identical(sig.Params().At(1).Type(), rq) ||
+		sig.Results().At(1).Type().String() != "error" {
+		return nil, false, false
+	}
+
+	ptr = types.NewMethodSet(t).Lookup(nil, "Execute") == nil
+	return sig.Results().At(0).Type(), ptr, true
+}
+
+// wrappers is the template for the generated wrappers.
+var wrappers = template.Must(template.New("wrappers").Parse(`package {{ .Package }}
+
+import (
+{{- range .Imports }}
+	"{{ . }}"
+{{- end }}
+)
+
+// {{ .Execute }} executes the specified {{ .Package }}.{{ .Request }} using the mediator,
+// returning the result of the registered command.
+func {{ .Execute }}(ctx context.Context, rq {{ .Request }}) ({{ .Result }}, error) {
+	return mediator.Execute(ctx, rq, new({{ .Result }}))
+}
+
+// {{ .Register }} registers the specified command to handle {{ .Package }}.{{ .Request }}
+// requests (e.g. {{ .Handlers }}).
+func {{ .Register }}(ctx context.Context, cmd mediator.CommandHandler[{{ .Request }}, {{ .Result }}]) error {
+	return mediator.RegisterCommand[{{ .Request }}, {{ .Result }}](ctx, cmd)
+}
+`))
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// stubs are the sources of packages imported by the packages in tests.
+var stubs = map[string]string{
+	mediatorPath: `package mediator
+
+import "context"
+
+type NoResultType *int
+
+type CommandHandler[TRequest any, TResult any] interface {
+	Execute(context.Context, TRequest) (TResult, error)
+}
+
+func RegisterCommand[TRequest any, TResult any](ctx context.Context, cmd CommandHandler[TRequest, TResult]) error {
+	return nil
+}
+
+func Execute[TRequest any, TResult any](ctx context.Context, rq TRequest, hint *TResult) (TResult, error) {
+	return *new(TResult), nil
+}
+`,
+	"example.com/models": `package models
+
+type Foo struct{}
+`,
+}
+
+// stubImporter imports stub packages, falling back to the default importer.
+type stubImporter struct {
+	fset *token.FileSet
+	pkgs map[string]*types.Package
+}
+
+func (imp *stubImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := imp.pkgs[path]; ok {
+		return pkg, nil
+	}
+	src, ok := stubs[path]
+	if !ok {
+		return importer.Default().Import(path)
+	}
+	pkg, err := check(imp, path, map[string]string{path + ".go": src})
+	imp.pkgs[path] = pkg
+	return pkg, err
+}
+
+// check type-checks a package from the specified sources.
+func check(imp *stubImporter, path string, srcs map[string]string) (*types.Package, error) {
+	files := []*ast.File{}
+	for name, src := range srcs {
+		f, err := parser.ParseFile(imp.fset, name, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	cfg := &types.Config{Importer: imp}
+	return cfg.Check(path, imp.fset, files, nil)
+}
+
+func TestGenerate(t *testing.T) {
+	// ARRANGE
+	opts := options{request: "Request", output: "mediator_gen.go", execute: "Execute", register: "Register"}
+
+	arrange := func(t *testing.T, srcs map[string]string) (*token.FileSet, *types.Package) {
+		t.Helper()
+		imp := &stubImporter{fset: token.NewFileSet(), pkgs: map[string]*types.Package{}}
+		pkg, err := check(imp, "example.com/getFoo", srcs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return imp.fset, pkg
+	}
+
+	t.Run("generates wrappers", func(t *testing.T) {
+		// ARRANGE
+		fset, pkg := arrange(t, map[string]string{"getfoo.go": `package getFoo
+
+import (
+	"context"
+
+	"example.com/models"
+)
+
+type Request struct{ Id string }
+
+type Command struct{}
+
+func (*Command) Execute(context.Context, Request) (*models.Foo, error) { return nil, nil }
+
+type unrelated struct{}
+
+func (unrelated) Execute(context.Context, string) (int, error) { return 0, nil }
+`})
+
+		// ACT
+		src, err := generate(fset, pkg, opts)
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wanted := `// Code generated by mediatorgen. DO NOT EDIT.
+
+package getFoo
+
+import (
+	"context"
+	"example.com/models"
+	"github.com/blugnu/mediator"
+)
+
+// Execute executes the specified getFoo.Request using the mediator,
+// returning the result of the registered command.
+func Execute(ctx context.Context, rq Request) (*models.Foo, error) {
+	return mediator.Execute(ctx, rq, new(*models.Foo))
+}
+
+// Register registers the specified command to handle getFoo.Request
+// requests (e.g. *Command).
+func Register(ctx context.Context, cmd mediator.CommandHandler[Request, *models.Foo]) error {
+	return mediator.RegisterCommand[Request, *models.Foo](ctx, cmd)
+}
+`
+		got := string(src)
+		if wanted != got {
+			t.Errorf("\nwanted %s\ngot    %s", wanted, got)
+		}
+	})
+
+	testcases := []struct {
+		name   string
+		src    string
+		result string
+	}{
+		{name: "no request type", src: `package getFoo`, result: "no request type"},
+		{name: "no handler", src: `package getFoo
+
+type Request struct{}
+`, result: "no handler for example.com/getFoo.Request"},
+		{name: "handlers with different results", src: `package getFoo
+
+import "context"
+
+type Request struct{}
+
+type A struct{}
+
+func (A) Execute(context.Context, Request) (int, error) { return 0, nil }
+
+type B struct{}
+
+func (B) Execute(context.Context, Request) (string, error) { return "", nil }
+`, result: "handlers of example.com/getFoo.Request return different result types: int and string"},
+		{name: "name already declared", src: `package getFoo
+
+import "context"
+
+type Request struct{}
+
+func Execute(context.Context, Request) (int, error) { return 0, nil }
+`, result: "getfoo.go:7:6: Execute is already declared in package getFoo"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// ARRANGE
+			fset, pkg := arrange(t, map[string]string{"getfoo.go": tc.src})
+
+			// ACT
+			_, err := generate(fset, pkg, opts)
+
+			// ASSERT
+			if err == nil || !strings.HasPrefix(err.Error(), tc.result) {
+				t.Errorf("\nwanted error starting %q\ngot    %v", tc.result, err)
+			}
+		})
+	}
+
+	t.Run("regenerates wrappers", func(t *testing.T) {
+		// ARRANGE
+		fset, pkg := arrange(t, map[string]string{
+			"getfoo.go": `package getFoo
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+)
+
+type Request struct{}
+
+type Command struct{}
+
+func (Command) Execute(context.Context, Request) (mediator.NoResultType, error) { return nil, nil }
+`,
+			"mediator_gen.go": `package getFoo
+
+func Execute() {}
+`,
+		})
+
+		// ACT
+		src, err := generate(fset, pkg, opts)
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(string(src), "(ctx context.Context, rq Request) (mediator.NoResultType, error)") {
+			t.Errorf("unexpected source:\n%s", src)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+// Command mediatorgen generates typed wrappers for packaged commands (see
+// .docs/packaged-commands.md), removing the need for a result type-hint when
+// executing a request and ensuring at compile-time that requests are executed
+// and registered with the correct result type.
+//
+// For each package declaring a Request type and a handler (a type with an
+// Execute(context.Context, Request) (TResult, error) method), mediatorgen
+// writes a file (mediator_gen.go) declaring:
+//
+//	// Execute executes the specified request using the mediator
+//	func Execute(ctx context.Context, rq Request) (TResult, error)
+//
+//	// Register registers a command to handle requests
+//	func Register(ctx context.Context, cmd mediator.CommandHandler[Request, TResult]) error
+//
+// mediatorgen is typically run using go generate, with a directive in the
+// command package:
+//
+//	//go:generate go run github.com/blugnu/mediator/tools/cmd/mediatorgen
+//
+// or once for all packaged commands:
+//
+//	//go:generate go run github.com/blugnu/mediator/tools/cmd/mediatorgen ./commands/...
+//
+// Usage:
+//
+//	mediatorgen [flags] [packages]
+//
+// Packages are specified as for the go command; the default is the package in
+// the current directory.  Packages which do not declare a request type are
+// ignored unless specified explicitly.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func main() {
+	opts := options{}
+	flag.StringVar(&opts.request, "request", "Request", "the name of the request type")
+	flag.StringVar(&opts.output, "output", "mediator_gen.go", "the name of the generated file")
+	flag.StringVar(&opts.execute, "execute", "Execute", "the name of the generated execute function")
+	flag.StringVar(&opts.register, "register", "Register", "the name of the generated register function")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: mediatorgen [flags] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(opts, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "mediatorgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the wrappers for the packaged commands in the specified
+// packages.
+func run(opts options, patterns []string) error {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	wildcard := false
+	for _, p := range patterns {
+		wildcard = wildcard || strings.Contains(p, "...")
+	}
+
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) == 0 {
+			continue
+		}
+		src, err := generate(pkg.Fset, pkg.Types, opts)
+		if errors.Is(err, errNoRequest) && wildcard {
+			continue
+		}
+		if err != nil {
+			if len(pkg.Errors) > 0 {
+				return fmt.Errorf("%s: %v (%w)", pkg.PkgPath, pkg.Errors[0], err)
+			}
+			return fmt.Errorf("%s: %w", pkg.PkgPath, err)
+		}
+
+		path := filepath.Join(filepath.Dir(pkg.GoFiles[0]), opts.output)
+		if err := os.WriteFile(path, src, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
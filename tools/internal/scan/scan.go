@@ -0,0 +1,245 @@
+// Package scan identifies the use of the mediator in the syntax of a
+// type-checked package: registrations of commands and execution of
+// requests.
+package scan
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// MediatorPath is the import path of the mediator package.
+const MediatorPath = "github.com/blugnu/mediator"
+
+// Kind identifies the kind of use of the mediator.
+type Kind int
+
+const (
+	Registration Kind = iota // a command is registered for a request type
+	Execution                // a request is executed
+	Equivalence              // pointer equivalence is enabled (see mediator.SetPointerEquivalence)
+)
+
+// Use is a use of the mediator identified in the syntax of a package.
+type Use struct {
+	Kind      Kind
+	Func      string     // the name of the mediator function called
+	Request   types.Type // the request type (the interface type, for interface registrations); nil if Kind is Equivalence
+	Result    types.Type // the result type; nil if Kind is Equivalence
+	Command   types.Type // the type of the command registered (registrations only)
+	Tenant    string     // the tenant for which the command is registered (tenant registrations only)
+	Name      string     // the name with which the command is registered, or the request executed (named commands only)
+	Dynamic   bool       // true if the tenant or name is not a constant, in which case Tenant or Name is the expression identifying it
+	Interface bool       // true if the command is registered for requests implementing an interface
+	Pos       token.Pos  // the position of the call
+}
+
+// registrationFuncs identifies the mediator functions which register a command.
+var registrationFuncs = map[string]bool{
+	"RegisterCommand":          true,
+	"RegisterTenantCommand":    true,
+	"RegisterNamedCommand":     true,
+	"RegisterInterfaceCommand": true,
+}
+
+// executionFuncs identifies the mediator functions which execute a request.
+var executionFuncs = map[string]bool{
+	"Execute":      true,
+	"ExecuteNamed": true,
+}
+
+// GeneratedHeader is the comment identifying a file generated by mediatorgen.
+const GeneratedHeader = "// Code generated by mediatorgen. DO NOT EDIT."
+
+// Generated returns true if the specified file was generated by mediatorgen.
+func Generated(file *ast.File) bool {
+	for _, c := range file.Comments {
+		if c.Pos() > file.Package {
+			break
+		}
+		for _, line := range c.List {
+			if line.Text == GeneratedHeader {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Test returns true if the specified file is a test file.
+func Test(fset *token.FileSet, file *ast.File) bool {
+	return strings.HasSuffix(fset.File(file.Pos()).Name(), "_test.go")
+}
+
+// Package returns the uses of the mediator in the specified files of a
+// package.  Test files and files generated by mediatorgen are ignored.
+func Package(fset *token.FileSet, files []*ast.File, info *types.Info) []Use {
+	result := []Use{}
+	for _, file := range files {
+		if Test(fset, file) || Generated(file) {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if use, ok := Call(call, info); ok {
+				result = append(result, use)
+			}
+			return true
+		})
+	}
+	return result
+}
+
+// Wrappers returns the uses of the mediator by the typed wrappers declared in
+// files generated by mediatorgen in the specified files of a package, keyed by
+// the wrapper function.  The position of each use is the position of the call
+// in the wrapper.
+func Wrappers(files []*ast.File, info *types.Info) map[*types.Func]Use {
+	result := map[*types.Func]Use{}
+	for _, file := range files {
+		if !Generated(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			fn, ok := info.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					if use, ok := Call(call, info); ok {
+						result[fn] = use
+						return false
+					}
+				}
+				return true
+			})
+		}
+	}
+	return result
+}
+
+// Call returns the use of the mediator by a call expression, if the call is to
+// a mediator function that registers a command or executes a request, or to
+// SetPointerEquivalence enabling pointer equivalence.  Pointer equivalence is
+// assumed to be enabled unless the argument is the constant false.
+func Call(call *ast.CallExpr, info *types.Info) (Use, bool) {
+	id := funcIdent(call.Fun)
+	if id == nil {
+		return Use{}, false
+	}
+
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != MediatorPath {
+		return Use{}, false
+	}
+
+	if fn.Name() == "SetPointerEquivalence" {
+		if len(call.Args) == 1 {
+			if tv, ok := info.Types[call.Args[0]]; ok && tv.Value != nil && tv.Value.Kind() == constant.Bool && !constant.BoolVal(tv.Value) {
+				return Use{}, false
+			}
+		}
+		return Use{Kind: Equivalence, Func: fn.Name(), Pos: call.Pos()}, true
+	}
+
+	inst, ok := info.Instances[id]
+	if !ok || inst.TypeArgs.Len() < 2 {
+		return Use{}, false
+	}
+
+	// calls within generic functions, instantiated with type parameters, do
+	// not identify specific request types
+	for i := 0; i < inst.TypeArgs.Len(); i++ {
+		if _, ok := inst.TypeArgs.At(i).(*types.TypeParam); ok {
+			return Use{}, false
+		}
+	}
+
+	use := Use{
+		Func:    fn.Name(),
+		Request: inst.TypeArgs.At(0),
+		Result:  inst.TypeArgs.At(1),
+		Pos:     call.Pos(),
+	}
+	switch {
+	case registrationFuncs[fn.Name()]:
+		use.Kind = Registration
+		if len(call.Args) > 1 {
+			use.Command = info.TypeOf(call.Args[len(call.Args)-1])
+		}
+		switch {
+		case fn.Name() == "RegisterTenantCommand" && len(call.Args) > 2:
+			use.Tenant, use.Dynamic = key(call.Args[1], info)
+		case fn.Name() == "RegisterNamedCommand" && len(call.Args) > 2:
+			use.Name, use.Dynamic = key(call.Args[1], info)
+		case fn.Name() == "RegisterInterfaceCommand":
+			use.Interface = true
+		}
+	case executionFuncs[fn.Name()]:
+		use.Kind = Execution
+		if fn.Name() == "ExecuteNamed" && len(call.Args) > 3 {
+			use.Name, use.Dynamic = key(call.Args[1], info)
+		}
+	default:
+		return Use{}, false
+	}
+	return use, true
+}
+
+// key returns the value of a constant string argument identifying a tenant
+// or name, or the expression if the argument is not a constant, in which case
+// dynamic is true.
+func key(arg ast.Expr, info *types.Info) (value string, dynamic bool) {
+	if tv, ok := info.Types[arg]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value), false
+	}
+	return types.ExprString(arg), true
+}
+
+// funcIdent returns the identifier of the function called by a call expression,
+// removing any package qualifier and explicit type arguments.
+func funcIdent(fun ast.Expr) *ast.Ident {
+	for {
+		switch x := fun.(type) {
+		case *ast.Ident:
+			return x
+		case *ast.SelectorExpr:
+			return x.Sel
+		case *ast.IndexExpr:
+			fun = x.X
+		case *ast.IndexListExpr:
+			fun = x.X
+		case *ast.ParenExpr:
+			fun = x.X
+		default:
+			return nil
+		}
+	}
+}
+
+// TypeName returns the name of a type qualified by the full path of its
+// package (if any).
+func TypeName(t types.Type) string {
+	if t == nil {
+		return ""
+	}
+	return types.TypeString(t, nil)
+}
+
+// Implements returns true if the specified request type implements the
+// specified interface type, for which a command is registered.
+func Implements(rq types.Type, iface types.Type) bool {
+	it, ok := iface.Underlying().(*types.Interface)
+	return ok && types.Implements(rq, it)
+}
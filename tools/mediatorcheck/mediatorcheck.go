@@ -0,0 +1,442 @@
+// Package mediatorcheck provides an analyzer which checks the registration
+// and execution of mediator commands:
+//
+//   - request types which are executed but never registered (and do not
+//     implement any interface for which a command is registered or, if the
+//     program enables pointer equivalence, point to a registered type);
+//   - request types which are registered more than once (or more than once
+//     for the same tenant or with the same name);
+//   - request types which are executed expecting a result type different to
+//     the result type of the registered command.
+//
+// Typed wrappers generated by mediatorgen are recognized: a call to a
+// generated Execute or Register function is treated as an execution or
+// registration of the request type.
+//
+// Each package records the registrations and executions it contains as a
+// package fact.  Problems involving a package and its dependencies are
+// reported at the position of the registration or execution involved.
+// Since a request type may be registered in any package of a program, request
+// types executed but never registered (and problems involving packages which
+// do not depend on each other) are reported when analyzing a main package,
+// at the declaration of the main function, identifying the position of the
+// registrations or executions involved.
+package mediatorcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/blugnu/mediator/tools/internal/scan"
+)
+
+// Analyzer checks the registration and execution of mediator commands.
+var Analyzer = &analysis.Analyzer{
+	Name:      "mediatorcheck",
+	Doc:       "check that mediator request types are registered once and executed with the registered result type",
+	URL:       "https://pkg.go.dev/github.com/blugnu/mediator/tools/mediatorcheck",
+	Run:       run,
+	FactTypes: []analysis.Fact{new(usesFact), new(wrapperFact)},
+}
+
+// use is a registration or execution recorded in a usesFact.
+type use struct {
+	Request   string   // the request type
+	Result    string   // the result type
+	Tenant    string   // the tenant for which the command is registered (if any)
+	Name      string   // the name with which the command is registered, or the request executed (if any)
+	Dynamic   bool     // true if the tenant or name is determined at runtime
+	Interface bool     // true if the command is registered for requests implementing the (interface) request type
+	Methods   []string // the method set of the request type (interface registrations and executions only)
+	Pos       string   // the position of the registration or execution
+	Reported  bool     // true if a problem with the use has been reported
+}
+
+// key identifies the registrations which conflict with a registration: those
+// for the same request type and tenant or name.  A registration for a tenant
+// or with a name determined at runtime does not conflict with any other
+// registration.
+func (u use) key() string {
+	switch {
+	case u.Dynamic:
+		return u.Request + " " + u.Pos
+	case u.Tenant != "":
+		return u.Request + " tenant " + strconv.Quote(u.Tenant)
+	case u.Name != "":
+		return u.Request + " name " + strconv.Quote(u.Name)
+	}
+	return u.Request
+}
+
+// describe returns a description of the request type of a registration or
+// execution, identifying the tenant or name (if any).
+func (u use) describe() string {
+	switch {
+	case u.Dynamic:
+		return "request type " + u.Request
+	case u.Tenant != "":
+		return fmt.Sprintf("request type %s for tenant %q", u.Request, u.Tenant)
+	case u.Name != "":
+		return fmt.Sprintf("request type %s with name %q", u.Request, u.Name)
+	}
+	return "request type " + u.Request
+}
+
+// registered returns true if any of the specified registrations of a request
+// type (or of the interfaces for which commands are registered) may execute
+// the specified execution.  A request executed with a name must be registered
+// with the name (or a name determined at runtime); any other request may be
+// executed by any registration (a named command may be selected by a command
+// selector, which is not identified) or by a command registered for an
+// interface implemented by the request type.
+func registered(regs []use, ifaces []use, e use) bool {
+	if e.Name == "" || e.Dynamic {
+		if len(regs) > 0 {
+			return true
+		}
+		if e.Name == "" {
+			for _, i := range ifaces {
+				if implements(e.Methods, i.Methods) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, r := range regs {
+		if r.Name == e.Name || (r.Name != "" && r.Dynamic) {
+			return true
+		}
+	}
+	return false
+}
+
+// implements returns true if a method set includes all of the methods of an
+// interface.
+func implements(methods []string, iface []string) bool {
+	set := map[string]bool{}
+	for _, m := range methods {
+		set[m] = true
+	}
+	for _, m := range iface {
+		if !set[m] {
+			return false
+		}
+	}
+	return true
+}
+
+// methods returns the method set of a type, identifying each method by its
+// name (qualified by its package, if not exported) and the types of its
+// parameters and results.
+func methods(t types.Type) []string {
+	result := []string{}
+	if it, ok := t.Underlying().(*types.Interface); ok {
+		for i := 0; i < it.NumMethods(); i++ {
+			result = append(result, method(it.Method(i)))
+		}
+		return result
+	}
+	ms := types.NewMethodSet(t)
+	for i := 0; i < ms.Len(); i++ {
+		if fn, ok := ms.At(i).Obj().(*types.Func); ok {
+			result = append(result, method(fn))
+		}
+	}
+	return result
+}
+
+// method returns the identity and signature of a method.
+func method(fn *types.Func) string {
+	sig := fn.Type().(*types.Signature)
+	tuple := func(t *types.Tuple, variadic bool) string {
+		s := make([]string, t.Len())
+		for i := range s {
+			s[i] = scan.TypeName(t.At(i).Type())
+		}
+		if variadic {
+			s[len(s)-1] = "..." + strings.TrimPrefix(s[len(s)-1], "[]")
+		}
+		return strings.Join(s, ", ")
+	}
+	return fmt.Sprintf("%s(%s) (%s)", fn.Id(), tuple(sig.Params(), sig.Variadic()), tuple(sig.Results(), false))
+}
+
+// usesFact records the registrations and executions in a package, and
+// whether the package enables pointer equivalence.
+type usesFact struct {
+	Registrations      []use
+	Executions         []use
+	PointerEquivalence bool
+}
+
+func (*usesFact) AFact() {}
+
+func (f *usesFact) String() string {
+	if f.PointerEquivalence {
+		return fmt.Sprintf("uses(%d registrations, %d executions, pointer equivalence)", len(f.Registrations), len(f.Executions))
+	}
+	return fmt.Sprintf("uses(%d registrations, %d executions)", len(f.Registrations), len(f.Executions))
+}
+
+// wrapperFact records that a function is a typed wrapper generated by
+// mediatorgen, which registers or executes a request type.
+type wrapperFact struct {
+	Kind    scan.Kind
+	Request string
+	Result  string
+}
+
+func (*wrapperFact) AFact() {}
+
+func (f *wrapperFact) String() string {
+	if f.Kind == scan.Registration {
+		return fmt.Sprintf("registers(%s, %s)", f.Request, f.Result)
+	}
+	return fmt.Sprintf("executes(%s, %s)", f.Request, f.Result)
+}
+
+// localUse is a registration or execution in the package being analyzed.
+type localUse struct {
+	*use
+	pos token.Pos
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	exportWrapperFacts(pass)
+
+	regs, execs := []localUse{}, []localUse{}
+	equivalence := false
+	add := func(kind scan.Kind, u use, pos token.Pos) {
+		u.Pos = pass.Fset.Position(pos).String()
+		lu := localUse{use: &u, pos: pos}
+		switch kind {
+		case scan.Registration:
+			regs = append(regs, lu)
+		case scan.Execution:
+			execs = append(execs, lu)
+		case scan.Equivalence:
+			equivalence = true
+		}
+	}
+	for _, u := range scan.Package(pass.Fset, pass.Files, pass.TypesInfo) {
+		lu := use{
+			Request:   scan.TypeName(u.Request),
+			Result:    scan.TypeName(u.Result),
+			Tenant:    u.Tenant,
+			Name:      u.Name,
+			Dynamic:   u.Dynamic,
+			Interface: u.Interface,
+		}
+		if u.Interface || (u.Kind == scan.Execution && u.Name == "") {
+			lu.Methods = methods(u.Request)
+		}
+		add(u.Kind, lu, u.Pos)
+	}
+	for _, file := range pass.Files {
+		if scan.Test(pass.Fset, file) || scan.Generated(file) {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				if fn := typeutil.StaticCallee(pass.TypesInfo, call); fn != nil {
+					if f := new(wrapperFact); pass.ImportObjectFact(fn, f) {
+						add(f.Kind, use{Request: f.Request, Result: f.Result}, call.Pos())
+					}
+				}
+			}
+			return true
+		})
+	}
+	sort.Slice(regs, func(i, j int) bool { return regs[i].pos < regs[j].pos })
+	sort.Slice(execs, func(i, j int) bool { return execs[i].pos < execs[j].pos })
+
+	// registrations visible to the package (in the package or its dependencies),
+	// by request type and by key
+	visible, keyed := map[string][]use{}, map[string][]use{}
+	for _, pf := range pass.AllPackageFacts() {
+		if f, ok := pf.Fact.(*usesFact); ok && pf.Package != pass.Pkg {
+			for _, r := range f.Registrations {
+				visible[r.Request] = append(visible[r.Request], r)
+				keyed[r.key()] = append(keyed[r.key()], r)
+			}
+		}
+	}
+
+	for _, r := range regs {
+		if prev := keyed[r.key()]; len(prev) > 0 {
+			r.Reported = true
+			pass.Reportf(r.pos, "%s is already registered (at %s)", r.describe(), prev[0].Pos)
+		}
+		visible[r.Request] = append(visible[r.Request], *r.use)
+		keyed[r.key()] = append(keyed[r.key()], *r.use)
+	}
+
+	for _, e := range execs {
+		if rs := visible[e.Request]; len(rs) > 0 && !hasResult(rs, e.Result) {
+			e.Reported = true
+			pass.Reportf(e.pos, "request type %s is executed expecting result %s but is registered with result %s (at %s)", e.Request, e.Result, rs[0].Result, rs[0].Pos)
+		}
+	}
+
+	if pass.Pkg.Name() == "main" {
+		checkProgram(pass, regs, execs, equivalence)
+	}
+
+	fact := &usesFact{PointerEquivalence: equivalence}
+	for _, r := range regs {
+		fact.Registrations = append(fact.Registrations, *r.use)
+	}
+	for _, e := range execs {
+		fact.Executions = append(fact.Executions, *e.use)
+	}
+	if len(fact.Registrations) > 0 || len(fact.Executions) > 0 || fact.PointerEquivalence {
+		pass.ExportPackageFact(fact)
+	}
+
+	return nil, nil
+}
+
+// exportWrapperFacts exports a wrapperFact for each function declared in a
+// file generated by mediatorgen which registers or executes a request type.
+func exportWrapperFacts(pass *analysis.Pass) {
+	for fn, u := range scan.Wrappers(pass.Files, pass.TypesInfo) {
+		pass.ExportObjectFact(fn, &wrapperFact{
+			Kind:    u.Kind,
+			Request: scan.TypeName(u.Request),
+			Result:  scan.TypeName(u.Result),
+		})
+	}
+}
+
+// checkProgram checks the registrations and executions of all packages in a
+// program, when analyzing the main package.  Problems not already reported
+// are reported at the declaration of the main function (or, for executions in
+// the main package itself, at the position of the execution).
+//
+// Every package of the program is a dependency of the main package, so any
+// execution in the main package with a different result type to a registration
+// for the same request type has already been reported.
+//
+// If any package of the program enables pointer equivalence, a request of
+// type *T executed without a name, for which no command is registered, is
+// treated as executed by the commands registered for T (other than named
+// commands).
+func checkProgram(pass *analysis.Pass, regs, execs []localUse, equivalence bool) {
+	allRegs := map[string][]use{}
+	allExecs := []use{}
+	for _, pf := range pass.AllPackageFacts() {
+		if f, ok := pf.Fact.(*usesFact); ok && pf.Package != pass.Pkg {
+			for _, r := range f.Registrations {
+				allRegs[r.Request] = append(allRegs[r.Request], r)
+			}
+			allExecs = append(allExecs, f.Executions...)
+			equivalence = equivalence || f.PointerEquivalence
+		}
+	}
+	for _, r := range regs {
+		allRegs[r.Request] = append(allRegs[r.Request], *r.use)
+	}
+	ifaces := []use{}
+	for _, rs := range allRegs {
+		for _, r := range rs {
+			if r.Interface {
+				ifaces = append(ifaces, r)
+			}
+		}
+	}
+
+	// executing returns the registrations which may execute a request
+	executing := func(e use) []use {
+		rs := allRegs[e.Request]
+		if len(rs) > 0 || !equivalence || e.Name != "" || !strings.HasPrefix(e.Request, "*") {
+			return rs
+		}
+		for _, r := range allRegs[strings.TrimPrefix(e.Request, "*")] {
+			if r.Name == "" && !r.Interface {
+				rs = append(rs, r)
+			}
+		}
+		return rs
+	}
+
+	at := mainPos(pass)
+
+	for _, e := range execs {
+		rs := executing(*e.use)
+		switch {
+		case !registered(rs, ifaces, *e.use):
+			pass.Reportf(e.pos, "%s is executed but never registered", e.describe())
+		case !e.Reported && len(rs) > 0 && !hasResult(rs, e.Result):
+			pass.Reportf(e.pos, "request type %s is executed expecting result %s but is registered with result %s (at %s)", e.Request, e.Result, rs[0].Result, rs[0].Pos)
+		}
+	}
+
+	sort.Slice(allExecs, func(i, j int) bool { return allExecs[i].Pos < allExecs[j].Pos })
+	for _, e := range allExecs {
+		rs := executing(e)
+		switch {
+		case !registered(rs, ifaces, e):
+			pass.Reportf(at, "%s: %s is executed but never registered", e.Pos, e.describe())
+		case !e.Reported && len(rs) > 0 && !hasResult(rs, e.Result):
+			pass.Reportf(at, "%s: request type %s is executed expecting result %s but is registered with result %s (at %s)", e.Pos, e.Request, e.Result, rs[0].Result, rs[0].Pos)
+		}
+	}
+
+	keyed := map[string][]use{}
+	for _, rs := range allRegs {
+		for _, r := range rs {
+			keyed[r.key()] = append(keyed[r.key()], r)
+		}
+	}
+	keys := make([]string, 0, len(keyed))
+	for key := range keyed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		unreported := []string{}
+		for _, r := range keyed[key] {
+			if !r.Reported {
+				unreported = append(unreported, r.Pos)
+			}
+		}
+		if len(unreported) > 1 {
+			sort.Strings(unreported)
+			pass.Reportf(at, "%s is registered more than once (at %s)", keyed[key][0].describe(), strings.Join(unreported, ", "))
+		}
+	}
+}
+
+// hasResult returns true if any of the specified registrations has the
+// specified result type.
+func hasResult(regs []use, result string) bool {
+	for _, r := range regs {
+		if r.Result == result {
+			return true
+		}
+	}
+	return false
+}
+
+// mainPos returns the position of the declaration of the main function of a
+// main package, or the package clause of the first file if there is no main
+// function.
+func mainPos(pass *analysis.Pass) token.Pos {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+				return fn.Name.Pos()
+			}
+		}
+	}
+	return pass.Files[0].Name.Pos()
+}
@@ -0,0 +1,41 @@
+package main // want package:`uses\(0 registrations, 5 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+
+	"example/audit"
+	"example/audited"
+	"example/bar"
+	"example/client"
+	"example/foo"
+	"example/getfoo"
+	"example/named"
+	"example/reg1"
+	"example/reg2"
+	"example/tenant"
+)
+
+type Local struct{}
+
+func main() { // want `request type example/shared.Request is registered more than once \(at .*reg1.go:12:6, .*reg2.go:12:6\)` `client.go:12:9: request type example/shared.Request is executed expecting result int but is registered with result string` `client.go:13:9: request type example/shared.Unregistered is executed but never registered` `named.go:24:9: request type example/named.Request with name "square" is executed but never registered` `audited.go:24:9: request type example/audited.PointerRequest is executed but never registered` `audited.go:25:9: request type example/audited.OtherRequest is executed but never registered`
+	ctx := context.Background()
+	foo.Register(ctx)
+	bar.Register(ctx)
+	reg1.Register(ctx)
+	reg2.Register(ctx)
+	client.Call(ctx)
+	tenant.Register(ctx, "tenant")
+	tenant.Call(ctx)
+	named.Register(ctx)
+	audit.Register(ctx)
+	audited.Call(ctx)
+	named.Call(ctx, "stripe")
+	_, _ = mediator.ExecuteNamed(ctx, "paypal", named.Request{}, new(int))
+	_, _ = mediator.ExecuteNamed(ctx, "klarna", named.Request{}, new(int)) // want `request type example/named.Request with name "klarna" is executed but never registered`
+
+	_, _ = mediator.Execute(ctx, foo.Request{}, new(*foo.Result))
+	_, _ = mediator.Execute(ctx, Local{}, new(int)) // want `request type example/app.Local is executed but never registered`
+	_, _ = getfoo.Execute(ctx, getfoo.Request{})    // want `request type example/getfoo.Request is executed but never registered`
+}
@@ -0,0 +1,19 @@
+package audit // want package:`uses\(1 registrations, 0 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+)
+
+type Auditable interface {
+	Audit(context.Context) []string
+}
+
+type Command struct{}
+
+func (Command) Execute(context.Context, Auditable) (int, error) { return 0, nil }
+
+func Register(ctx context.Context) {
+	_ = mediator.RegisterInterfaceCommand[Auditable, int](ctx, 0, Command{})
+}
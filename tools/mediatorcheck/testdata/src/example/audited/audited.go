@@ -0,0 +1,26 @@
+package audited // want package:`uses\(0 registrations, 4 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+)
+
+type Request struct{}
+
+func (Request) Audit(context.Context) []string { return nil }
+
+type PointerRequest struct{}
+
+func (*PointerRequest) Audit(context.Context) []string { return nil }
+
+type OtherRequest struct{}
+
+func (OtherRequest) Audit(string) []string { return nil }
+
+func Call(ctx context.Context) {
+	_, _ = mediator.Execute(ctx, Request{}, new(int))
+	_, _ = mediator.Execute(ctx, &PointerRequest{}, new(int))
+	_, _ = mediator.Execute(ctx, PointerRequest{}, new(int))
+	_, _ = mediator.Execute(ctx, OtherRequest{}, new(int))
+}
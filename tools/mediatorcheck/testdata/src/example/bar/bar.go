@@ -0,0 +1,26 @@
+package bar // want package:`uses\(3 registrations, 2 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+
+	"example/foo"
+)
+
+type Request struct{}
+
+type Command struct{}
+
+func (Command) Execute(context.Context, Request) (int, error) { return 0, nil }
+
+func Register(ctx context.Context) {
+	_ = mediator.RegisterCommand[Request, int](ctx, Command{})
+	_ = mediator.RegisterCommand[Request, int](ctx, Command{})                  // want `request type example/bar.Request is already registered \(at .*bar.go:18:6\)`
+	_ = mediator.RegisterCommand[foo.Request, *foo.Result](ctx, &foo.Command{}) // want `request type example/foo.Request is already registered \(at .*foo.go:18:6\)`
+}
+
+func Call(ctx context.Context) {
+	_, _ = mediator.Execute(ctx, Request{}, new(int))
+	_, _ = mediator.Execute(ctx, Request{}, new(string)) // want `request type example/bar.Request is executed expecting result string but is registered with result int \(at .*bar.go:18:6\)`
+}
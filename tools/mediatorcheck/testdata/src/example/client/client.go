@@ -0,0 +1,18 @@
+package client // want package:`uses\(0 registrations, 2 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+
+	"example/shared"
+)
+
+func Call(ctx context.Context) {
+	_, _ = mediator.Execute(ctx, shared.Request{}, new(int))
+	_, _ = mediator.Execute(ctx, shared.Unregistered{}, new(int))
+}
+
+func Generic[TRequest any, TResult any](ctx context.Context, rq TRequest) {
+	_, _ = mediator.Execute(ctx, rq, new(TResult))
+}
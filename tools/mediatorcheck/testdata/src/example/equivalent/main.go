@@ -0,0 +1,19 @@
+package main // want package:`uses\(0 registrations, 2 executions, pointer equivalence\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+
+	"example/pointers"
+)
+
+func main() { // want `pointers.go:35:9: request type \*example/pointers.Request is executed expecting result string but is registered with result int` `pointers.go:36:9: request type \*example/pointers.Named is executed but never registered`
+	ctx := context.Background()
+	mediator.SetPointerEquivalence(true)
+	pointers.Register(ctx)
+	pointers.Call(ctx)
+
+	_, _ = mediator.Execute(ctx, &pointers.Request{}, new(int))
+	_, _ = mediator.Execute(ctx, &pointers.Request{}, new(bool)) // want `request type \*example/pointers.Request is executed expecting result bool but is registered with result int`
+}
@@ -0,0 +1,19 @@
+package foo // want package:`uses\(1 registrations, 0 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+)
+
+type Request struct{}
+
+type Result struct{}
+
+type Command struct{}
+
+func (*Command) Execute(context.Context, Request) (*Result, error) { return nil, nil }
+
+func Register(ctx context.Context) {
+	_ = mediator.RegisterCommand[Request, *Result](ctx, &Command{})
+}
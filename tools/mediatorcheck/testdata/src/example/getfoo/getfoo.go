@@ -0,0 +1,11 @@
+package getfoo
+
+import "context"
+
+type Request struct{}
+
+type Result struct{}
+
+type Command struct{}
+
+func (*Command) Execute(context.Context, Request) (*Result, error) { return nil, nil }
@@ -0,0 +1,26 @@
+package named // want package:`uses\(3 registrations, 4 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+)
+
+type Request struct{}
+
+type Command struct{}
+
+func (Command) Execute(context.Context, Request) (int, error) { return 0, nil }
+
+func Register(ctx context.Context) {
+	_ = mediator.RegisterNamedCommand[Request, int](ctx, "stripe", Command{})
+	_ = mediator.RegisterNamedCommand[Request, int](ctx, "paypal", Command{})
+	_ = mediator.RegisterNamedCommand[Request, int](ctx, "stripe", Command{}) // want `request type example/named.Request with name "stripe" is already registered \(at .*named.go:16:6\)`
+}
+
+func Call(ctx context.Context, name string) {
+	_, _ = mediator.Execute(ctx, Request{}, new(int))
+	_, _ = mediator.ExecuteNamed(ctx, "stripe", Request{}, new(int))
+	_, _ = mediator.ExecuteNamed(ctx, "square", Request{}, new(int))
+	_, _ = mediator.ExecuteNamed(ctx, name, Request{}, new(string)) // want `request type example/named.Request is executed expecting result string but is registered with result int \(at .*named.go:16:6\)`
+}
@@ -0,0 +1,18 @@
+package main // want package:`uses\(0 registrations, 1 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+
+	"example/pointers"
+)
+
+func main() { // want `pointers.go:34:9: request type \*example/pointers.Request is executed but never registered` `pointers.go:35:9: request type \*example/pointers.Request is executed but never registered` `pointers.go:36:9: request type \*example/pointers.Named is executed but never registered`
+	ctx := context.Background()
+	mediator.SetPointerEquivalence(false)
+	pointers.Register(ctx)
+	pointers.Call(ctx)
+
+	_, _ = mediator.Execute(ctx, &pointers.Request{}, new(int)) // want `request type \*example/pointers.Request is executed but never registered`
+}
@@ -0,0 +1,38 @@
+package pointers // want package:`uses\(3 registrations, 4 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+)
+
+type Request struct{}
+
+type Named struct{}
+
+type Pointer struct{}
+
+type Command struct{}
+
+func (Command) Execute(context.Context, Request) (int, error) { return 0, nil }
+
+type NamedCommand struct{}
+
+func (NamedCommand) Execute(context.Context, Named) (int, error) { return 0, nil }
+
+type PointerCommand struct{}
+
+func (PointerCommand) Execute(context.Context, *Pointer) (int, error) { return 0, nil }
+
+func Register(ctx context.Context) {
+	_ = mediator.RegisterCommand[Request, int](ctx, Command{})
+	_ = mediator.RegisterNamedCommand[Named, int](ctx, "name", NamedCommand{})
+	_ = mediator.RegisterCommand[*Pointer, int](ctx, PointerCommand{})
+}
+
+func Call(ctx context.Context) {
+	_, _ = mediator.Execute(ctx, &Request{}, new(int))
+	_, _ = mediator.Execute(ctx, &Request{}, new(string))
+	_, _ = mediator.Execute(ctx, &Named{}, new(int))
+	_, _ = mediator.Execute(ctx, &Pointer{}, new(int))
+}
@@ -0,0 +1,13 @@
+package reg1 // want package:`uses\(1 registrations, 0 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+
+	"example/shared"
+)
+
+func Register(ctx context.Context) {
+	_ = mediator.RegisterCommand[shared.Request, string](ctx, shared.Command{})
+}
@@ -0,0 +1,11 @@
+package shared
+
+import "context"
+
+type Request struct{}
+
+type Unregistered struct{}
+
+type Command struct{}
+
+func (Command) Execute(context.Context, Request) (string, error) { return "", nil }
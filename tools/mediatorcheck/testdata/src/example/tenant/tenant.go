@@ -0,0 +1,25 @@
+package tenant // want package:`uses\(5 registrations, 1 executions\)`
+
+import (
+	"context"
+
+	"github.com/blugnu/mediator"
+)
+
+type Request struct{}
+
+type Command struct{}
+
+func (Command) Execute(context.Context, Request) (int, error) { return 0, nil }
+
+func Register(ctx context.Context, tenant string) {
+	_ = mediator.RegisterTenantCommand[Request, int](ctx, "acme", Command{})
+	_ = mediator.RegisterTenantCommand[Request, int](ctx, "other", Command{})
+	_ = mediator.RegisterTenantCommand[Request, int](ctx, "acme", Command{}) // want `request type example/tenant.Request for tenant "acme" is already registered \(at .*tenant.go:16:6\)`
+	_ = mediator.RegisterTenantCommand[Request, int](ctx, tenant, Command{})
+	_ = mediator.RegisterTenantCommand[Request, int](ctx, tenant, Command{})
+}
+
+func Call(ctx context.Context) {
+	_, _ = mediator.Execute(ctx, Request{}, new(int))
+}
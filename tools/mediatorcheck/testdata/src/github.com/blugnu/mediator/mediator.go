@@ -0,0 +1,34 @@
+// Package mediator is a stub of the mediator package for testing.
+package mediator
+
+import "context"
+
+type CommandHandler[TRequest any, TResult any] interface {
+	Execute(context.Context, TRequest) (TResult, error)
+}
+
+func RegisterCommand[TRequest any, TResult any](ctx context.Context, cmd CommandHandler[TRequest, TResult]) error {
+	return nil
+}
+
+func Execute[TRequest any, TResult any](ctx context.Context, rq TRequest, hint *TResult) (TResult, error) {
+	return *new(TResult), nil
+}
+
+func RegisterTenantCommand[TRequest any, TResult any](ctx context.Context, tenant string, cmd CommandHandler[TRequest, TResult]) error {
+	return nil
+}
+
+func RegisterNamedCommand[TRequest any, TResult any](ctx context.Context, name string, cmd CommandHandler[TRequest, TResult]) error {
+	return nil
+}
+
+func ExecuteNamed[TRequest any, TResult any](ctx context.Context, name string, rq TRequest, hint *TResult) (TResult, error) {
+	return *new(TResult), nil
+}
+
+func RegisterInterfaceCommand[TInterface any, TResult any](ctx context.Context, priority int, cmd CommandHandler[TInterface, TResult]) error {
+	return nil
+}
+
+func SetPointerEquivalence(enabled bool) {}
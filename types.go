@@ -60,3 +60,18 @@ type ConfigurationChecker interface {
 type Validator[TRequest any] interface {
 	Validate(context.Context, TRequest) error
 }
+
+// Authorizer[TRequest] is an optional interface that may be implemented
+// by a command to determine whether the principal associated with the
+// context of a request is permitted to execute that request.
+//
+// If implemented, the mediator will call the Authorize function before
+// calling any Validator; any error returned by Authorize is returned
+// (wrapped in a ForbiddenError, unless it is already an UnauthorizedError
+// or ForbiddenError).
+//
+// Authorize is not called if there is no principal associated with the
+// context; an UnauthorizedError is returned instead.
+type Authorizer[TRequest any] interface {
+	Authorize(ctx context.Context, principal any, rq TRequest) error
+}
@@ -0,0 +1,116 @@
+package mediator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrNoDatabase is returned by Execute when a request is sent to a
+// Transactional command but no database has been established using
+// SetDatabase.
+var ErrNoDatabase = errors.New("no database established for unit of work")
+
+// TxBeginner is the interface implemented by a database able to begin a
+// transaction.  It is satisfied by *sql.DB and *sql.Conn.
+type TxBeginner interface {
+	BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Transactional is an optional interface that may be implemented by a
+// command to indicate that the command must be executed in a unit of work.
+//
+// If implemented, the mediator begins a transaction (with the options
+// returned by TxOptions, which may be nil) before validating the request.
+// The transaction is available to the command (and any commands executed
+// by it) using the Tx function.  If the command returns an error or panics,
+// the transaction is rolled back, otherwise it is committed.
+//
+// If a unit of work has already begun when a Transactional command is
+// executed (i.e. the command is executed by some other Transactional
+// command), the command joins the existing unit of work; the transaction
+// is committed or rolled back when the unit of work that began it completes.
+type Transactional interface {
+	TxOptions() *sql.TxOptions
+}
+
+// unitOfWorkKey is the context key for the unit of work.
+type unitOfWorkKey struct{}
+
+// unitOfWork holds the state of a unit of work.
+type unitOfWork struct {
+	tx     *sql.Tx
+	outbox outboxMessages
+}
+
+var database TxBeginner
+
+// SetDatabase establishes the database used to begin transactions for
+// Transactional commands.  Typically this will be an *sql.DB.
+func SetDatabase(db TxBeginner) {
+	database = db
+}
+
+// Tx returns the transaction of any unit of work associated with the
+// specified context.  If there is no unit of work, the function returns
+// nil and false.
+func Tx(ctx context.Context) (*sql.Tx, bool) {
+	if uow, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork); ok {
+		return uow.tx, true
+	}
+	return nil, false
+}
+
+// inUnitOfWork calls the specified function in a unit of work if the command
+// implements Transactional.  If the command does not implement Transactional
+// or a unit of work has already begun, the function is called directly.
+//
+// Otherwise a transaction is begun and the function called with a context
+// holding the unit of work.  If the function returns an error the transaction
+// is rolled back and the error returned.  Otherwise any notifications
+// enqueued in the unit of work are saved to the outbox and the transaction
+// committed, returning the result (unless saving the outbox or the commit
+// fails, in which case that error is returned).  If the function panics, the
+// transaction is rolled back before the panic is allowed to continue.
+func inUnitOfWork[TResult any](ctx context.Context, cmd any, fn func(context.Context) (TResult, error)) (TResult, error) {
+	t, ok := cmd.(Transactional)
+	if !ok {
+		return fn(ctx)
+	}
+	if _, ok := Tx(ctx); ok {
+		return fn(ctx)
+	}
+
+	z := *new(TResult)
+	if database == nil {
+		return z, ErrNoDatabase
+	}
+
+	tx, err := database.BeginTx(ctx, t.TxOptions())
+	if err != nil {
+		return z, err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	uow := &unitOfWork{tx: tx}
+	result, err := fn(context.WithValue(ctx, unitOfWorkKey{}, uow))
+	if err != nil {
+		return z, err
+	}
+
+	if err := uow.saveOutbox(ctx); err != nil {
+		return z, err
+	}
+
+	committed = true
+	if err := tx.Commit(); err != nil {
+		return z, err
+	}
+	return result, nil
+}
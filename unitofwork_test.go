@@ -0,0 +1,258 @@
+package mediator
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// uowtestdb is a fake database driver connector used for testing units of
+// work; it records the transactions begun, committed and rolled back.
+type uowtestdb struct {
+	begun      int
+	committed  int
+	rolledback int
+	commiterr  error
+}
+
+func (db *uowtestdb) Connect(context.Context) (driver.Conn, error) { return uowtestconn{db}, nil }
+func (db *uowtestdb) Driver() driver.Driver                        { return nil }
+
+type uowtestconn struct{ db *uowtestdb }
+
+func (uowtestconn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (uowtestconn) Close() error                        { return nil }
+func (c uowtestconn) Begin() (driver.Tx, error)         { c.db.begun++; return uowtesttx(c), nil }
+
+type uowtesttx struct{ db *uowtestdb }
+
+func (tx uowtesttx) Commit() error   { tx.db.committed++; return tx.db.commiterr }
+func (tx uowtesttx) Rollback() error { tx.db.rolledback++; return nil }
+
+// uowtestcmd is a Transactional command used for testing units of work.
+type uowtestcmd struct {
+	txs    []*sql.Tx
+	nested bool
+	valerr error
+	err    error
+	panic  bool
+}
+
+func (cmd *uowtestcmd) TxOptions() *sql.TxOptions { return nil }
+
+func (cmd *uowtestcmd) Validate(ctx context.Context, _ uint) error {
+	tx, _ := Tx(ctx)
+	cmd.txs = append(cmd.txs, tx)
+	return cmd.valerr
+}
+
+func (cmd *uowtestcmd) Execute(ctx context.Context, _ uint) (NoResultType, error) {
+	tx, _ := Tx(ctx)
+	cmd.txs = append(cmd.txs, tx)
+	if cmd.nested {
+		if _, err := Execute(ctx, int8(1), NoResult); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.panic {
+		panic("command panicked")
+	}
+	return nil, cmd.err
+}
+
+// uowtestnestedcmd is a Transactional command executed by uowtestcmd.
+type uowtestnestedcmd struct {
+	tx *sql.Tx
+}
+
+func (cmd *uowtestnestedcmd) TxOptions() *sql.TxOptions { return nil }
+
+func (cmd *uowtestnestedcmd) Execute(ctx context.Context, _ int8) (NoResultType, error) {
+	cmd.tx, _ = Tx(ctx)
+	return nil, nil
+}
+
+func TestUnitOfWork(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	arrange := func(t *testing.T, cmd *uowtestcmd) *uowtestdb {
+		t.Helper()
+		db := &uowtestdb{}
+		SetDatabase(sql.OpenDB(db))
+		unreg, err := register(ctx, uint(0), cmd)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		t.Cleanup(func() { SetDatabase(nil); unreg() })
+		return db
+	}
+
+	assertCounts := func(t *testing.T, db *uowtestdb, begun, committed, rolledback int) {
+		t.Helper()
+		if db.begun != begun || db.committed != committed || db.rolledback != rolledback {
+			t.Errorf("\nwanted begun %d, committed %d, rolled back %d\ngot    begun %d, committed %d, rolled back %d",
+				begun, committed, rolledback,
+				db.begun, db.committed, db.rolledback)
+		}
+	}
+
+	t.Run("when command is not Transactional", func(t *testing.T) {
+		// ARRANGE
+		mock := MockCommand[uint, NoResultType]()
+		defer mock.Unregister()
+
+		// ACT
+		result, err := inUnitOfWork(ctx, mock, func(ctx context.Context) (bool, error) {
+			_, ok := Tx(ctx)
+			return ok, nil
+		})
+
+		// ASSERT
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if result {
+			t.Error("wanted no transaction, got transaction")
+		}
+	})
+
+	t.Run("when no database is established", func(t *testing.T) {
+		// ARRANGE
+		cmd := &uowtestcmd{}
+		unreg, _ := register(ctx, uint(0), cmd)
+		defer unreg()
+
+		// ACT
+		_, err := Execute(ctx, uint(1), NoResult)
+
+		// ASSERT
+		wanted := ErrNoDatabase
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when command succeeds", func(t *testing.T) {
+		// ARRANGE
+		cmd := &uowtestcmd{}
+		db := arrange(t, cmd)
+
+		// ACT
+		_, err := Execute(ctx, uint(1), NoResult)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		t.Run("commits the transaction", func(t *testing.T) {
+			assertCounts(t, db, 1, 1, 0)
+		})
+
+		t.Run("provides the transaction to Validate and Execute", func(t *testing.T) {
+			if len(cmd.txs) != 2 || cmd.txs[0] == nil || cmd.txs[0] != cmd.txs[1] {
+				t.Errorf("wanted same transaction in Validate and Execute, got %v", cmd.txs)
+			}
+		})
+	})
+
+	t.Run("when commit fails", func(t *testing.T) {
+		// ARRANGE
+		cmd := &uowtestcmd{}
+		db := arrange(t, cmd)
+		db.commiterr = errors.New("commit failed")
+
+		// ACT
+		_, err := Execute(ctx, uint(1), NoResult)
+
+		// ASSERT
+		wanted := db.commiterr
+		got := err
+		if !errors.Is(got, wanted) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when validation fails", func(t *testing.T) {
+		// ARRANGE
+		cmd := &uowtestcmd{valerr: errors.New("invalid")}
+		db := arrange(t, cmd)
+
+		// ACT
+		_, err := Execute(ctx, uint(1), NoResult)
+
+		// ASSERT
+		if !errors.As(err, new(ValidationError)) {
+			t.Errorf("wanted ValidationError, got %T (%[1]v)", err)
+		}
+		assertCounts(t, db, 1, 0, 1)
+	})
+
+	t.Run("when command returns an error", func(t *testing.T) {
+		// ARRANGE
+		cmd := &uowtestcmd{err: errors.New("command failed")}
+		db := arrange(t, cmd)
+
+		// ACT
+		_, err := Execute(ctx, uint(1), NoResult)
+
+		// ASSERT
+		wanted := cmd.err
+		got := err
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+		assertCounts(t, db, 1, 0, 1)
+	})
+
+	t.Run("when command panics", func(t *testing.T) {
+		// ARRANGE
+		cmd := &uowtestcmd{panic: true}
+		db := arrange(t, cmd)
+
+		// ACT
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("did not panic")
+				}
+			}()
+			_, _ = Execute(ctx, uint(1), NoResult)
+		}()
+
+		// ASSERT
+		assertCounts(t, db, 1, 0, 1)
+	})
+
+	t.Run("when command executes a nested Transactional command", func(t *testing.T) {
+		// ARRANGE
+		cmd := &uowtestcmd{nested: true}
+		db := arrange(t, cmd)
+
+		nested := &uowtestnestedcmd{}
+		unreg, _ := register(ctx, int8(0), nested)
+		defer unreg()
+
+		// ACT
+		_, err := Execute(ctx, uint(1), NoResult)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		t.Run("nested command joins the transaction", func(t *testing.T) {
+			wanted := cmd.txs[1]
+			got := nested.tx
+			if wanted != got {
+				t.Errorf("\nwanted %p\ngot    %p", wanted, got)
+			}
+		})
+
+		t.Run("transaction is begun and committed once", func(t *testing.T) {
+			assertCounts(t, db, 1, 1, 0)
+		})
+	})
+}
@@ -0,0 +1,83 @@
+package mediator
+
+import (
+	"context"
+	"reflect"
+)
+
+// wrappedCommand[TRequest, TResult] is a command established using WrapCommand,
+// executing requests using the wrapping command.
+type wrappedCommand[TRequest any, TResult any] struct {
+	CommandHandler[TRequest, TResult]
+	cmd any
+}
+
+// validatingWrappedCommand[TRequest, TResult] is a command established using
+// WrapCommand where the wrapping command also implements Validator.
+type validatingWrappedCommand[TRequest any, TResult any] struct {
+	wrappedCommand[TRequest, TResult]
+	validator Validator[TRequest]
+}
+
+// wrapped satisfies the wrapper interface, so that optional interfaces which
+// determine how a request is executed are those of the registered command.
+func (w wrappedCommand[TRequest, TResult]) wrapped() any {
+	return w.cmd
+}
+
+// Validate satisfies the Validator interface, calling the Validate method of
+// the wrapping command.
+func (w validatingWrappedCommand[TRequest, TResult]) Validate(ctx context.Context, rq TRequest) error {
+	return w.validator.Validate(ctx, rq)
+}
+
+// WrapCommand[TRequest, TResult] replaces the command registered for the
+// request type in the global registry with the command returned by the
+// specified function, which is called with the registered command (e.g. to
+// observe the calls to a real command in a test; see the mediatortest
+// package).  A function is returned which restores the original registration.
+// The original registration is restored only if the wrapper is still
+// registered; if the wrapper has since been unregistered or replaced (e.g.
+// by unregistering a mock command) the function does nothing.
+//
+// Requests are validated by the wrapping command only if it implements
+// Validator.  The registered command continues to determine whether requests
+// are authorized and executed in a unit of work, and identifies the handler of
+// requests in the audit trail and hooks.
+//
+// If no command is registered for the request type a NoCommandForRequestTypeError
+// is returned; if the registered command does not return the result type a
+// ResultTypeError is returned.
+//
+// As with RegisterCommand, the global registry is not safe for concurrent
+// modification; commands must not be wrapped (or the original registration
+// restored) in tests running in parallel with other tests that register
+// commands or execute requests using the global registry.
+func WrapCommand[TRequest any, TResult any](wrap func(CommandHandler[TRequest, TResult]) CommandHandler[TRequest, TResult]) (func(), error) {
+	rqt := reflect.TypeOf(*new(TRequest))
+	reg, ok := commands[rqt]
+	if !ok {
+		return nil, &NoCommandForRequestTypeError{request: *new(TRequest), nearMisses: nearMisses(context.Background(), rqt)}
+	}
+	cmd, ok := reg.(CommandHandler[TRequest, TResult])
+	if !ok {
+		return nil, &ResultTypeError{command: reg, result: *new(TResult), request: rqt, resultType: reflect.TypeOf((*TResult)(nil)).Elem()}
+	}
+
+	// the wrapper is registered by reference, so that it can be identified
+	// when restoring the original registration
+	var installed any
+	w := wrappedCommand[TRequest, TResult]{CommandHandler: wrap(cmd), cmd: reg}
+	if v, ok := w.CommandHandler.(Validator[TRequest]); ok {
+		installed = &validatingWrappedCommand[TRequest, TResult]{wrappedCommand: w, validator: v}
+	} else {
+		installed = &w
+	}
+	commands[rqt] = installed
+
+	return func() {
+		if current, ok := commands[rqt]; ok && current == installed {
+			commands[rqt] = reg
+		}
+	}, nil
+}
@@ -0,0 +1,173 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// wraptestcmd is a command used for testing wrapped commands.
+type wraptestcmd struct{}
+
+func (wraptestcmd) Validate(_ context.Context, rq int) error {
+	if rq < 0 {
+		return errors.New("negative")
+	}
+	return nil
+}
+
+func (wraptestcmd) Execute(_ context.Context, rq int) (int, error) {
+	return rq * 2, nil
+}
+
+// wraptestwrapper is a command wrapping another command, which does not
+// implement Validator.
+type wraptestwrapper struct {
+	cmd CommandHandler[int, int]
+}
+
+func (w wraptestwrapper) Execute(ctx context.Context, rq int) (int, error) {
+	result, err := w.cmd.Execute(ctx, rq)
+	return result + 1, err
+}
+
+// wraptestvalidatingwrapper is a command wrapping another command, which
+// implements Validator.
+type wraptestvalidatingwrapper struct {
+	wraptestwrapper
+}
+
+func (w wraptestvalidatingwrapper) Validate(context.Context, int) error {
+	return errors.New("rejected by wrapper")
+}
+
+func TestWrapCommand(t *testing.T) {
+	// ARRANGE
+	ctx := context.Background()
+
+	arrange := func(t *testing.T) {
+		t.Helper()
+		unreg, err := register(ctx, 0, wraptestcmd{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		t.Cleanup(unreg)
+	}
+
+	t.Run("when the wrapper does not implement Validator", func(t *testing.T) {
+		// ARRANGE
+		arrange(t)
+		restore, err := WrapCommand(func(cmd CommandHandler[int, int]) CommandHandler[int, int] {
+			return wraptestwrapper{cmd}
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer restore()
+
+		// ACT
+		result, err := Execute(ctx, -1, new(int))
+
+		// ASSERT
+		t.Run("executes using the wrapper", func(t *testing.T) {
+			wanted := -1
+			got := result
+			if err != nil || wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v (%v)", wanted, got, err)
+			}
+		})
+
+		t.Run("does not validate", func(t *testing.T) {
+			if _, ok := commands[reflect.TypeOf(0)].(Validator[int]); ok {
+				t.Error("wrapped command implements Validator")
+			}
+		})
+
+		t.Run("identifies the registered command", func(t *testing.T) {
+			wanted := any(wraptestcmd{})
+			got := unwrap(commands[reflect.TypeOf(0)])
+			if wanted != got {
+				t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+			}
+		})
+	})
+
+	t.Run("when the wrapper implements Validator", func(t *testing.T) {
+		// ARRANGE
+		arrange(t)
+		restore, _ := WrapCommand(func(cmd CommandHandler[int, int]) CommandHandler[int, int] {
+			return wraptestvalidatingwrapper{wraptestwrapper{cmd}}
+		})
+		defer restore()
+
+		// ACT
+		_, err := Execute(ctx, 1, new(int))
+
+		// ASSERT
+		wanted := ValidationError{E: errors.New("rejected by wrapper")}
+		got := err
+		if !reflect.DeepEqual(wanted, got) {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("restores the registration", func(t *testing.T) {
+		// ARRANGE
+		arrange(t)
+		restore, _ := WrapCommand(func(cmd CommandHandler[int, int]) CommandHandler[int, int] {
+			return wraptestwrapper{cmd}
+		})
+
+		// ACT
+		restore()
+
+		// ASSERT
+		wanted := any(wraptestcmd{})
+		got := commands[reflect.TypeOf(0)]
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("when the command is unregistered before restoring", func(t *testing.T) {
+		// ARRANGE
+		unreg, _ := register(ctx, 0, wraptestcmd{})
+		restore, _ := WrapCommand(func(cmd CommandHandler[int, int]) CommandHandler[int, int] {
+			return wraptestwrapper{cmd}
+		})
+		unreg()
+
+		// ACT
+		restore()
+
+		// ASSERT
+		if cmd, ok := commands[reflect.TypeOf(0)]; ok {
+			delete(commands, reflect.TypeOf(0))
+			t.Errorf("wanted no command registered, got %#v", cmd)
+		}
+	})
+
+	t.Run("when no command is registered", func(t *testing.T) {
+		// ACT
+		restore, err := WrapCommand(func(cmd CommandHandler[int, int]) CommandHandler[int, int] { return cmd })
+
+		// ASSERT
+		if restore != nil || !errors.As(err, new(*NoCommandForRequestTypeError)) {
+			t.Errorf("wanted NoCommandForRequestTypeError, got %v", err)
+		}
+	})
+
+	t.Run("when result type is different", func(t *testing.T) {
+		// ARRANGE
+		arrange(t)
+
+		// ACT
+		restore, err := WrapCommand(func(cmd CommandHandler[int, string]) CommandHandler[int, string] { return cmd })
+
+		// ASSERT
+		if restore != nil || !errors.As(err, new(*ResultTypeError)) {
+			t.Errorf("wanted ResultTypeError, got %v", err)
+		}
+	})
+}